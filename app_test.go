@@ -0,0 +1,336 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// productFixtureFields names the Product JSON member each invalid fixture in test_requests.json must be rejected for
+var productFixtureFields = map[string]string{
+	"invalid_name_too_short":        "name",
+	"invalid_name_non_alphanumeric": "name",
+	"invalid_description_too_short": "description",
+	"invalid_price_negative":        "price",
+	"invalid_category":              "category",
+	"invalid_sku_wrong_format":      "sku",
+	"invalid_sku_wrong_length":      "sku",
+	"invalid_tags_empty":            "tags",
+	"invalid_tags_too_many":         "tags",
+}
+
+// serveApp sends one request to a fresh sample application serving products and decodes the
+// JSON response into v
+func serveApp(t *testing.T, products *ProductStore, req *http.Request, v any) *httptest.ResponseRecorder {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	newApp(products).ServeHTTP(w, req)
+
+	if v != nil {
+		if err := json.Unmarshal(w.Body.Bytes(), v); err != nil {
+			t.Fatalf("decoding response %q: %v", w.Body.String(), err)
+		}
+	}
+	return w
+}
+
+// TestProductFixtures replays every fixture in test_requests.json against POST /product
+func TestProductFixtures(t *testing.T) {
+	defer silenceStdout(t)()
+
+	for _, fx := range loadFixtures(t) {
+		t.Run(fx.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/product", bytes.NewReader(fx.body))
+			req.Header.Set("Content-Type", "application/json")
+
+			var body struct {
+				Error   string       `json:"error"`
+				Fields  []FieldError `json:"fields"`
+				Product Product      `json:"product"`
+			}
+			w := serveApp(t, NewProductStore(), req, &body)
+
+			switch {
+			case strings.HasPrefix(fx.name, "valid_"):
+				if w.Code != http.StatusCreated {
+					t.Fatalf("status = %d, want %d; body %s", w.Code, http.StatusCreated, w.Body)
+				}
+				var sent Product
+				json.Unmarshal(fx.body, &sent)
+				if body.Product.ID == 0 || body.Product.Name != sent.Name || body.Product.SKU != sent.SKU {
+					t.Errorf("created product = %+v, want the posted product with an ID", body.Product)
+				}
+
+			case strings.HasPrefix(fx.name, "invalid_"):
+				field, ok := productFixtureFields[fx.name]
+				if !ok {
+					t.Fatalf("no expected field for %s; add it to productFixtureFields", fx.name)
+				}
+				if w.Code != http.StatusUnprocessableEntity {
+					t.Fatalf("status = %d, want %d; body %s", w.Code, http.StatusUnprocessableEntity, w.Body)
+				}
+				if len(body.Fields) == 0 {
+					t.Fatalf("no failing fields in %s", w.Body)
+				}
+				// Each invalid fixture breaks exactly one field, possibly through several rules
+				for _, failure := range body.Fields {
+					if failure.Field != field || failure.Rule == "" || failure.Message == "" {
+						t.Errorf("failure %+v, want a rule and message for field %s only", failure, field)
+					}
+				}
+
+			default:
+				t.Fatalf("fixture names must start with valid_ or invalid_")
+			}
+		})
+	}
+}
+
+// TestProductValidationFailures verifies every failing rule is reported, not just the first one
+func TestProductValidationFailures(t *testing.T) {
+	defer silenceStdout(t)()
+
+	tests := []struct {
+		name   string
+		body   string
+		status int
+		want   []FieldError
+	}{
+		{
+			name:   "every field invalid",
+			body:   `{"name":"P!","description":"Short","price":-1,"category":"toys","sku":"abc","tags":[]}`,
+			status: http.StatusUnprocessableEntity,
+			want: []FieldError{
+				{Field: "name", Rule: "minlen", Param: "3"},
+				{Field: "name", Rule: "alphanumeric"},
+				{Field: "description", Rule: "minlen", Param: "10"},
+				{Field: "price", Rule: "gt", Param: "0"},
+				{Field: "category", Rule: "oneof", Param: "electronics clothing books home sports"},
+				{Field: "sku", Rule: "regex", Param: `^[A-Z]{3}\d{5}$`},
+				{Field: "tags", Rule: "minlen", Param: "1"},
+			},
+		},
+		{
+			name:   "empty object",
+			body:   `{}`,
+			status: http.StatusUnprocessableEntity,
+			want: []FieldError{
+				{Field: "name", Rule: "required"},
+				{Field: "name", Rule: "minlen", Param: "3"},
+				{Field: "description", Rule: "required"},
+				{Field: "description", Rule: "minlen", Param: "10"},
+				{Field: "price", Rule: "required"},
+				{Field: "price", Rule: "gt", Param: "0"},
+				{Field: "category", Rule: "required"},
+				{Field: "category", Rule: "oneof", Param: "electronics clothing books home sports"},
+				{Field: "sku", Rule: "required"},
+				{Field: "sku", Rule: "regex", Param: `^[A-Z]{3}\d{5}$`},
+				{Field: "tags", Rule: "required"},
+				{Field: "tags", Rule: "minlen", Param: "1"},
+			},
+		},
+		{
+			name:   "malformed JSON",
+			body:   `{"name": `,
+			status: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/product", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+
+			var body struct {
+				Fields []FieldError `json:"fields"`
+			}
+			w := serveApp(t, NewProductStore(), req, &body)
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d; body %s", w.Code, tt.status, w.Body)
+			}
+
+			var got []FieldError
+			for _, failure := range body.Fields {
+				got = append(got, FieldError{Field: failure.Field, Rule: failure.Rule, Param: failure.Param})
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("failures = %+v\nwant %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// postProduct sends p to POST /product
+func postProduct(t *testing.T, products *ProductStore, p Product) *httptest.ResponseRecorder {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodPost, "/product", bytes.NewReader(mustMarshal(p)))
+	req.Header.Set("Content-Type", "application/json")
+	return serveApp(t, products, req, nil)
+}
+
+func TestCreateProduct(t *testing.T) {
+	defer silenceStdout(t)()
+
+	products := NewProductStore()
+
+	first, second := sampleProduct, sampleProduct
+	second.SKU = "ABC54321"
+
+	tests := []struct {
+		name    string
+		product Product
+		status  int
+		wantID  int
+	}{
+		{"first product", first, http.StatusCreated, 1},
+		{"second product", second, http.StatusCreated, 2},
+		{"duplicate SKU", first, http.StatusConflict, 0},
+	}
+
+	for _, tt := range tests {
+		w := postProduct(t, products, tt.product)
+		if w.Code != tt.status {
+			t.Fatalf("%s: status = %d, want %d; body %s", tt.name, w.Code, tt.status, w.Body)
+		}
+
+		var body struct {
+			Product Product `json:"product"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &body)
+		if body.Product.ID != tt.wantID {
+			t.Errorf("%s: ID = %d, want %d", tt.name, body.Product.ID, tt.wantID)
+		}
+	}
+}
+
+func TestGetProduct(t *testing.T) {
+	products := NewProductStore()
+	created, err := products.Create(sampleProduct)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		target string
+		status int
+	}{
+		{"/product/1", http.StatusOK},
+		{"/product/2", http.StatusNotFound},
+		{"/product/abc", http.StatusBadRequest},
+		{"/product/0", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		w := serveApp(t, products, httptest.NewRequest(http.MethodGet, tt.target, nil), nil)
+		if w.Code != tt.status {
+			t.Errorf("%s: status = %d, want %d; body %s", tt.target, w.Code, tt.status, w.Body)
+			continue
+		}
+		if tt.status != http.StatusOK {
+			continue
+		}
+
+		var body struct {
+			Product Product `json:"product"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &body)
+		if !reflect.DeepEqual(body.Product, created) {
+			t.Errorf("%s: product = %+v, want %+v", tt.target, body.Product, created)
+		}
+	}
+}
+
+// BenchmarkSampleAppCRUD runs the sample application as a CRUD workload: creating products
+// with unique SKUs, then reading, listing, searching and patching in a catalog of 1000
+func BenchmarkSampleAppCRUD(b *testing.B) {
+	defer silenceStdout(b)()
+
+	b.Run("Create", func(b *testing.B) {
+		app := newApp(NewProductStore())
+		bodies := make([][]byte, b.N)
+		for i := range bodies {
+			p := sampleProduct
+			p.SKU = benchmarkSKU(i)
+			bodies[i] = mustMarshal(p)
+		}
+
+		b.ResetTimer()
+		b.ReportAllocs()
+
+		for i := 0; i < b.N; i++ {
+			req := httptest.NewRequest(http.MethodPost, "/product", bytes.NewReader(bodies[i]))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			app.ServeHTTP(w, req)
+			if w.Code != http.StatusCreated {
+				b.Fatalf("status = %d; body %s", w.Code, w.Body)
+			}
+		}
+	})
+
+	catalog := NewProductStore()
+	for i := 0; i < 1000; i++ {
+		p := sampleProduct
+		p.SKU = benchmarkSKU(i)
+		p.Price = float64(i%500) + 0.99
+		p.InStock = i%3 != 0
+		if _, err := catalog.Create(p); err != nil {
+			b.Fatal(err)
+		}
+	}
+	app := newApp(catalog)
+
+	reads := []struct{ name, target string }{
+		{"Get", "/product/500"},
+		{"List", "/products?page=2&limit=20"},
+		{"ListFilteredSorted", "/products?category=electronics&in_stock=true&min_price=100&max_price=400&sort=-price&limit=50"},
+		{"Search", "/search?q=sample+gadget&limit=20"},
+	}
+	for _, read := range reads {
+		b.Run(read.name, func(b *testing.B) {
+			req := httptest.NewRequest(http.MethodGet, read.target, nil)
+
+			b.ResetTimer()
+			b.ReportAllocs()
+
+			for i := 0; i < b.N; i++ {
+				w := httptest.NewRecorder()
+				app.ServeHTTP(w, req)
+				if w.Code != http.StatusOK {
+					b.Fatalf("status = %d; body %s", w.Code, w.Body)
+				}
+			}
+		})
+	}
+
+	b.Run("Patch", func(b *testing.B) {
+		patch := []byte(`{"price":19.99,"tags":["sale"]}`)
+
+		b.ResetTimer()
+		b.ReportAllocs()
+
+		for i := 0; i < b.N; i++ {
+			req := httptest.NewRequest(http.MethodPatch, "/product/500", bytes.NewReader(patch))
+			req.Header.Set("Content-Type", "application/merge-patch+json")
+			w := httptest.NewRecorder()
+			app.ServeHTTP(w, req)
+			if w.Code != http.StatusOK {
+				b.Fatalf("status = %d; body %s", w.Code, w.Body)
+			}
+		}
+	})
+}
+
+// benchmarkSKU returns the i-th SKU matching Product's pattern: three letters and five digits
+func benchmarkSKU(i int) string {
+	letters := []byte{byte('A' + i/100000/676%26), byte('A' + i/100000/26%26), byte('A' + i/100000%26)}
+	return fmt.Sprintf("%s%05d", letters, i%100000)
+}
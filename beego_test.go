@@ -1,319 +1,228 @@
 package main
 
 import (
-	"bytes"
-	"encoding/json"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
-	"strconv"
+	"regexp"
+	"sync"
 	"testing"
 
 	"github.com/beego/beego/v2/server/web"
+	beecontext "github.com/beego/beego/v2/server/web/context"
 )
 
-// BeegoController for handling routes
-type BeegoController struct {
-	web.Controller
-}
+// beegoDefaultConfig is a snapshot of Beego's default configuration, taken before any benchmark
+// touches the global web.BConfig. It is a shallow copy; its maps and slices must not be modified.
+var beegoDefaultConfig = *web.BConfig
 
-// Simple GET handler
-func (c *BeegoController) Get() {
-	c.Ctx.WriteString("Hello, Beego Framework!")
-}
+// beegoFramework adapts Beego's functional router to the Framework interface.
+// Every instance owns its own HttpServer and config, so routes never leak between benchmarks
+// through the global web.BeeApp.
+type beegoFramework struct {
+	app *web.HttpServer
 
-// User controller for user routes
-type BeegoUserController struct {
-	web.Controller
+	initChains sync.Once
 }
 
-func (c *BeegoUserController) Get() {
-	id := c.Ctx.Input.Param(":id")
-	user := User{
-		ID:    1,
-		Name:  "John Doe",
-		Email: "john@example.com",
-	}
-
-	response := map[string]interface{}{
-		"id":   id,
-		"user": user,
-	}
-
-	c.Data["json"] = response
-	c.ServeJSON()
+func newBeegoFramework() Framework {
+	return newBeegoFrameworkInstance()
 }
 
-func (c *BeegoUserController) Post() {
-	var user User
-	if err := json.Unmarshal(c.Ctx.Input.RequestBody, &user); err != nil {
-		c.Data["json"] = map[string]string{"error": "Invalid JSON"}
-		c.Ctx.ResponseWriter.WriteHeader(http.StatusBadRequest)
-		c.ServeJSON()
-		return
-	}
+func newBeegoFrameworkInstance() *beegoFramework {
+	cfg := beegoDefaultConfig
 
-	user.ID = 123
-	response := map[string]interface{}{
-		"id":    user.ID,
-		"name":  user.Name,
-		"email": user.Email,
-	}
+	// Disable logs for benchmarking
+	cfg.Log.AccessLogs = false
+	cfg.RunMode = web.PROD
+	// Beego only fills Input.RequestBody, which BindJSON reads, when this is enabled
+	cfg.CopyRequestBody = true
 
-	c.Ctx.ResponseWriter.WriteHeader(http.StatusCreated)
-	c.Data["json"] = response
-	c.ServeJSON()
-}
+	// Drop the static directories an earlier instance set on the global config
+	web.BConfig.WebConfig.StaticDir = beegoDefaultConfig.WebConfig.StaticDir
 
-// Multiple params controller
-type BeegoMultiParamsController struct {
-	web.Controller
+	return &beegoFramework{app: web.NewHttpServerWithCfg(&cfg)}
 }
 
-func (c *BeegoMultiParamsController) Get() {
-	userID := c.Ctx.Input.Param(":id")
-	postID := c.Ctx.Input.Param(":postId")
-
-	response := map[string]string{
-		"userId": userID,
-		"postId": postID,
-	}
-
-	c.Data["json"] = response
-	c.ServeJSON()
+func (f *beegoFramework) GET(path string, h HandlerFunc) {
+	f.Handle(http.MethodGet, path, h)
 }
 
-// Search controller for query params
-type BeegoSearchController struct {
-	web.Controller
+func (f *beegoFramework) POST(path string, h HandlerFunc) {
+	f.Handle(http.MethodPost, path, h)
 }
 
-func (c *BeegoSearchController) Get() {
-	query := c.GetString("q")
-	limit := c.GetString("limit")
-
-	response := map[string]string{
-		"query": query,
-		"limit": limit,
-	}
-
-	c.Data["json"] = response
-	c.ServeJSON()
+func (f *beegoFramework) Handle(method, path string, h HandlerFunc) {
+	path, patterns := compileRegexRoute(path)
+	f.app.Handlers.AddMethod(method, path, beegoHandler(h, patterns))
 }
 
-// setupBeegoApp creates a new Beego app instance for benchmarking
-func setupBeegoApp() {
-	// Disable logs for benchmarking
-	web.BConfig.Log.AccessLogs = false
-	web.BConfig.RunMode = "prod"
-
-	// Simple GET handler
-	web.Router("/", &BeegoController{})
-
-	// JSON response handler
-	web.Router("/user/:id", &BeegoUserController{})
-
-	// POST handler
-	web.Router("/users", &BeegoUserController{})
-
-	// Multiple route parameters
-	web.Router("/users/:id/posts/:postId", &BeegoMultiParamsController{})
-
-	// Query parameters
-	web.Router("/search", &BeegoSearchController{})
+// Use inserts m as a filter chain on every path
+func (f *beegoFramework) Use(m MiddlewareFunc) {
+	f.app.InsertFilterChain("/*", func(next web.FilterFunc) web.FilterFunc {
+		return func(ctx *beecontext.Context) {
+			err := m(beegoContext{ctx: ctx}, func() error {
+				next(ctx)
+				return nil
+			})
+			if err != nil {
+				ctx.Output.SetStatus(http.StatusInternalServerError)
+				ctx.Output.Body([]byte(err.Error()))
+			}
+		}
+	})
 }
 
-// Beego Benchmark simple GET request
-func BenchmarkBeegoSimpleGET(b *testing.B) {
-	setupBeegoApp()
-	req := httptest.NewRequest("GET", "/", nil)
-
-	b.ResetTimer()
-	b.ReportAllocs()
-
-	for i := 0; i < b.N; i++ {
-		w := httptest.NewRecorder()
-		web.BeeApp.Handlers.ServeHTTP(w, req)
-	}
+// Static points Beego's static directories at root. Beego looks static files up in the global
+// web.BConfig rather than the instance config, so the map is replaced there and only the last
+// Static call is served, until the next instance restores the default; the map is replaced
+// rather than modified because beegoDefaultConfig shares it.
+func (f *beegoFramework) Static(prefix, root string) {
+	web.BConfig.WebConfig.StaticDir = map[string]string{prefix: root}
 }
 
-// Beego Benchmark GET request with route parameter
-func BenchmarkBeegoGetWithParam(b *testing.B) {
-	setupBeegoApp()
-	req := httptest.NewRequest("GET", "/user/123", nil)
-
-	b.ResetTimer()
-	b.ReportAllocs()
-
-	for i := 0; i < b.N; i++ {
-		w := httptest.NewRecorder()
-		web.BeeApp.Handlers.ServeHTTP(w, req)
-	}
+func (f *beegoFramework) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// Beego only links filter chains when its server starts, which never happens in-process
+	f.initChains.Do(f.app.Handlers.Init)
+	f.app.Handlers.ServeHTTP(w, r)
 }
 
-// Beego Benchmark GET request with multiple route parameters
-func BenchmarkBeegoGetWithMultipleParams(b *testing.B) {
-	setupBeegoApp()
-	req := httptest.NewRequest("GET", "/users/123/posts/456", nil)
-
-	b.ResetTimer()
-	b.ReportAllocs()
-
-	for i := 0; i < b.N; i++ {
-		w := httptest.NewRecorder()
-		web.BeeApp.Handlers.ServeHTTP(w, req)
-	}
+// routeCount returns the number of routes in the instance's route table
+func (f *beegoFramework) routeCount() int {
+	return len(f.app.Handlers.GetAllControllerInfo())
 }
 
-// Beego Benchmark GET request with query parameters
-func BenchmarkBeegoGetWithQueryParams(b *testing.B) {
-	setupBeegoApp()
-	req := httptest.NewRequest("GET", "/search?q=golang&limit=10", nil)
-
-	b.ResetTimer()
-	b.ReportAllocs()
-
-	for i := 0; i < b.N; i++ {
-		w := httptest.NewRecorder()
-		web.BeeApp.Handlers.ServeHTTP(w, req)
+func beegoHandler(h HandlerFunc, patterns []*regexp.Regexp) web.HandleFunc {
+	return func(ctx *beecontext.Context) {
+		if err := h(beegoContext{ctx: ctx, patterns: patterns}); err != nil {
+			ctx.Output.SetStatus(http.StatusInternalServerError)
+			ctx.Output.Body([]byte(err.Error()))
+		}
 	}
 }
 
-// Beego Benchmark POST request with JSON payload
-func BenchmarkBeegoPostWithJSON(b *testing.B) {
-	setupBeegoApp()
-
-	user := User{
-		Name:  "Test User",
-		Email: "test@example.com",
-	}
-
-	jsonData, _ := json.Marshal(user)
-
-	b.ResetTimer()
-	b.ReportAllocs()
-
-	for i := 0; i < b.N; i++ {
-		req := httptest.NewRequest("POST", "/users", bytes.NewBuffer(jsonData))
-		req.Header.Set("Content-Type", "application/json")
-		w := httptest.NewRecorder()
-		web.BeeApp.Handlers.ServeHTTP(w, req)
-	}
+// beegoContext implements Context on top of Beego's *context.Context
+type beegoContext struct {
+	ctx      *beecontext.Context
+	patterns []*regexp.Regexp
 }
 
-// Dynamic route controllers for routing benchmarks
-type BeegoRouteController struct {
-	web.Controller
-	RouteIndex int
+func (c beegoContext) Param(name string) string {
+	return c.ctx.Input.Param(":" + name)
 }
 
-func (c *BeegoRouteController) Get() {
-	c.Ctx.WriteString("Route " + strconv.Itoa(c.RouteIndex))
+func (c beegoContext) RegexParam(index int) (string, error) {
+	return regexParam(c, c.patterns, index)
 }
 
-// Beego Benchmark routing performance with different number of routes
-func BenchmarkBeegoRouting10Routes(b *testing.B) {
-	benchmarkBeegoRouting(b, 10)
+func (c beegoContext) Query(name string) string {
+	return c.ctx.Input.Query(name)
 }
 
-func BenchmarkBeegoRouting100Routes(b *testing.B) {
-	benchmarkBeegoRouting(b, 100)
+func (c beegoContext) BindJSON(v any) error {
+	return c.ctx.BindJSON(v)
 }
 
-func BenchmarkBeegoRouting1000Routes(b *testing.B) {
-	benchmarkBeegoRouting(b, 1000)
+// BindForm reads the form Beego parsed before routing, multipart bodies included
+func (c beegoContext) BindForm(v any) error {
+	return c.ctx.BindForm(v)
 }
 
-func benchmarkBeegoRouting(b *testing.B, numRoutes int) {
-	web.BConfig.Log.AccessLogs = false
-	web.BConfig.RunMode = "prod"
-
-	// Create multiple routes
-	for i := 0; i < numRoutes; i++ {
-		route := "/route" + strconv.Itoa(i)
-		controller := &BeegoRouteController{RouteIndex: i}
-		web.Router(route, controller)
-	}
-
-	// Test the last route (worst case scenario)
-	req := httptest.NewRequest("GET", "/route"+strconv.Itoa(numRoutes-1), nil)
-
-	b.ResetTimer()
-	b.ReportAllocs()
+// BindQuery uses Beego's form decoder on the query string alone; ctx.BindForm would mix in the body
+func (c beegoContext) BindQuery(v any) error {
+	return beecontext.ParseForm(c.ctx.Request.URL.Query(), v)
+}
 
-	for i := 0; i < b.N; i++ {
-		w := httptest.NewRecorder()
-		web.BeeApp.Handlers.ServeHTTP(w, req)
+func (c beegoContext) FormFile(name string) (*multipart.FileHeader, error) {
+	file, header, err := c.ctx.Request.FormFile(name)
+	if err != nil {
+		return nil, err
 	}
+	file.Close()
+	return header, nil
 }
 
-// Beego Benchmark concurrent requests
-func BenchmarkBeegoConcurrentRequests(b *testing.B) {
-	setupBeegoApp()
-	req := httptest.NewRequest("GET", "/", nil)
-
-	b.ResetTimer()
-	b.ReportAllocs()
-
-	b.RunParallel(func(pb *testing.PB) {
-		for pb.Next() {
-			w := httptest.NewRecorder()
-			web.BeeApp.Handlers.ServeHTTP(w, req)
-		}
-	})
+func (c beegoContext) JSON(code int, body H) error {
+	c.ctx.Output.SetStatus(code)
+	return c.ctx.Output.JSON(body, false, false)
 }
 
-// Data controller for payload benchmarks
-type BeegoDataController struct {
-	web.Controller
+func (c beegoContext) String(code int, s string) error {
+	c.ctx.Output.Header("Content-Type", "text/plain; charset=utf-8")
+	c.ctx.Output.SetStatus(code)
+	return c.ctx.Output.Body([]byte(s))
 }
 
-func (c *BeegoDataController) Post() {
-	var data map[string]interface{}
-	if err := json.Unmarshal(c.Ctx.Input.RequestBody, &data); err != nil {
-		c.Data["json"] = map[string]string{"error": "Invalid JSON"}
-		c.Ctx.ResponseWriter.WriteHeader(http.StatusBadRequest)
-		c.ServeJSON()
-		return
-	}
-
-	c.Data["json"] = data
-	c.ServeJSON()
+func (c beegoContext) Method() string {
+	return c.ctx.Input.Method()
 }
 
-// Beego Benchmark memory allocations for different payload sizes
-func BenchmarkBeegoSmallPayload(b *testing.B) {
-	benchmarkBeegoPayloadSize(b, 100)
+func (c beegoContext) Path() string {
+	return c.ctx.Input.URL()
 }
 
-func BenchmarkBeegoMediumPayload(b *testing.B) {
-	benchmarkBeegoPayloadSize(b, 1024)
+func (c beegoContext) Header(name string) string {
+	return c.ctx.Input.Header(name)
 }
 
-func BenchmarkBeegoLargePayload(b *testing.B) {
-	benchmarkBeegoPayloadSize(b, 10240)
+func (c beegoContext) SetHeader(name, value string) {
+	c.ctx.Output.Header(name, value)
 }
 
-func benchmarkBeegoPayloadSize(b *testing.B, size int) {
-	web.BConfig.Log.AccessLogs = false
-	web.BConfig.RunMode = "prod"
+func (c beegoContext) Stream(code int, contentType string, write StreamFunc) error {
+	c.ctx.Output.Header("Content-Type", contentType)
+	c.ctx.ResponseWriter.WriteHeader(code)
+	return write(c.ctx.ResponseWriter, http.NewResponseController(c.ctx.ResponseWriter).Flush)
+}
 
-	web.Router("/data", &BeegoDataController{})
+// TestBeegoRouteTableIsolated verifies each Beego instance holds exactly the routes its scenario registered,
+// even after other scenarios have registered routes on their own instances
+func TestBeegoRouteTableIsolated(t *testing.T) {
+	for _, sc := range scenarios {
+		want := 0
+		sc.routes(routeCounter{count: &want})
 
-	// Create payload of specified size
-	payload := make(map[string]string)
-	for i := 0; i < size/10; i++ { // Approximate size control
-		payload["key"+strconv.Itoa(i)] = "value" + strconv.Itoa(i)
+		// Register twice on separate instances; the second must not see the first one's routes
+		for i := 0; i < 2; i++ {
+			f := newBeegoFrameworkInstance()
+			sc.routes(f)
+			if got := f.routeCount(); got != want {
+				t.Errorf("%s: instance %d has %d routes, want %d", sc.name, i, got, want)
+			}
+		}
 	}
 
-	jsonData, _ := json.Marshal(payload)
-
-	b.ResetTimer()
-	b.ReportAllocs()
+	if got := len(web.BeeApp.Handlers.GetAllControllerInfo()); got != 0 {
+		t.Errorf("global web.BeeApp has %d routes, want 0", got)
+	}
 
-	for i := 0; i < b.N; i++ {
-		req := httptest.NewRequest("POST", "/data", bytes.NewBuffer(jsonData))
-		req.Header.Set("Content-Type", "application/json")
+	// Static directories live in the global config; a later instance must not serve them
+	root := writeStaticDir(t)
+	target := staticPrefix + "/" + staticFiles[0].name
+	for i, tt := range []struct {
+		static bool
+		want   int
+	}{{true, http.StatusOK}, {false, http.StatusNotFound}} {
+		f := newBeegoFrameworkInstance()
+		if tt.static {
+			f.Static(staticPrefix, root)
+		}
 		w := httptest.NewRecorder()
-		web.BeeApp.Handlers.ServeHTTP(w, req)
+		f.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+		if w.Code != tt.want {
+			t.Errorf("instance %d: GET %s = %d, want %d", i, target, w.Code, tt.want)
+		}
 	}
 }
+
+// routeCounter is a Framework that only counts route registrations
+type routeCounter struct {
+	http.Handler
+	count *int
+}
+
+func (r routeCounter) GET(string, HandlerFunc)            { *r.count++ }
+func (r routeCounter) POST(string, HandlerFunc)           { *r.count++ }
+func (r routeCounter) Handle(string, string, HandlerFunc) { *r.count++ }
+func (r routeCounter) Use(MiddlewareFunc)                 {}
+func (r routeCounter) Static(string, string)              {}
@@ -0,0 +1,192 @@
+// Command benchgate saves named baselines of `go test -bench` results and fails when a later
+// run regresses against one, so tree-framework upgrades can be checked before they are merged.
+//
+// Usage:
+//
+//	go test -run=^$ -bench=Frameworks/Tree/ -benchmem -count=10 | go run ./cmd/benchgate save -name v1.2.0
+//	go test -run=^$ -bench=Frameworks/Tree/ -benchmem -count=10 | go run ./cmd/benchgate compare -name v1.2.0
+//
+// compare exits with status 1 when a significant regression beyond the thresholds is found or a
+// gated benchmark of the baseline has no result in the new run, and with status 2 on usage or
+// input errors. Run compare with the same -bench pattern the baseline was saved with.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"tree-framework-benchmark/internal/benchresult"
+)
+
+const treeModule = "github.com/catalinfl/tree-framework"
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	var (
+		regressed bool
+		err       error
+	)
+	switch os.Args[1] {
+	case "save":
+		err = save(os.Args[2:])
+	case "compare":
+		regressed, err = compare(os.Args[2:], os.Stdout)
+	default:
+		usage()
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "benchgate:", err)
+		os.Exit(2)
+	}
+	if regressed {
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: benchgate save -name NAME [flags] [bench.txt ...]")
+	fmt.Fprintln(os.Stderr, "       benchgate compare -name NAME [flags] [bench.txt ...]")
+	os.Exit(2)
+}
+
+func save(args []string) error {
+	fs := flag.NewFlagSet("save", flag.ExitOnError)
+	dir := fs.String("dir", "baselines", "directory baselines are stored in")
+	name := fs.String("name", "", "baseline name, e.g. the tree-framework version (required)")
+	fs.Parse(args)
+
+	path, err := benchresult.BaselinePath(*dir, *name)
+	if err != nil {
+		return err
+	}
+	results, err := benchresult.ParseFiles(fs.Args(), os.Stdin)
+	if err != nil {
+		return err
+	}
+
+	// The version is informational only, so a missing go.mod is not an error
+	treeVersion, _ := benchresult.ModuleVersion("go.mod", treeModule)
+
+	b := &benchresult.Baseline{Name: *name, TreeVersion: treeVersion, Created: time.Now().UTC(), Results: results}
+	if err := benchresult.SaveBaseline(path, b); err != nil {
+		return err
+	}
+
+	fmt.Printf("saved %d results to %s\n", len(results), path)
+	return nil
+}
+
+func compare(args []string, w io.Writer) (bool, error) {
+	fs := flag.NewFlagSet("compare", flag.ExitOnError)
+	dir := fs.String("dir", "baselines", "directory baselines are stored in")
+	name := fs.String("name", "", "baseline to compare against (required)")
+	framework := fs.String("framework", "Tree", "framework whose regressions fail the gate; empty gates every framework")
+	timeThreshold := fs.Float64("time", 5, "maximum allowed ns/op increase in percent")
+	allocsThreshold := fs.Float64("allocs", 0, "maximum allowed allocs/op increase in percent")
+	alpha := fs.Float64("alpha", 0.05, "significance level of the Mann-Whitney U test")
+	fs.Parse(args)
+
+	path, err := benchresult.BaselinePath(*dir, *name)
+	if err != nil {
+		return false, err
+	}
+	baseline, err := benchresult.LoadBaseline(path)
+	if err != nil {
+		return false, err
+	}
+	results, err := benchresult.ParseFiles(fs.Args(), os.Stdin)
+	if err != nil {
+		return false, err
+	}
+
+	comparisons := benchresult.Compare(baseline.Results, results, benchresult.NsPerOp, benchresult.BytesPerOp, benchresult.AllocsPerOp)
+	if len(comparisons) == 0 {
+		return false, fmt.Errorf("no benchmarks in common with baseline %q", *name)
+	}
+
+	thresholds := map[string]float64{
+		benchresult.NsPerOp:     *timeThreshold,
+		benchresult.AllocsPerOp: *allocsThreshold,
+	}
+
+	fmt.Fprintf(w, "baseline %s (tree-framework %s, saved %s)\n\n", baseline.Name, orUnknown(baseline.TreeVersion), baseline.Created.Format(time.DateOnly))
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "name\tunit\told\tnew\tdelta\tp\tn\t")
+
+	gated := func(key string) bool {
+		return *framework == "" || strings.Split(key, "/")[1] == *framework
+	}
+
+	regressions, fewSamples := 0, false
+	for _, c := range comparisons {
+		threshold, hasThreshold := thresholds[c.Unit]
+		regressed := gated(c.Key) && hasThreshold && c.Significant(*alpha) && c.Delta > threshold
+		if regressed {
+			regressions++
+		}
+		if gated(c.Key) && c.Unit == benchresult.NsPerOp && (c.OldSamples < 4 || c.NewSamples < 4) {
+			fewSamples = true
+		}
+
+		delta := "~"
+		if c.Significant(*alpha) {
+			delta = fmt.Sprintf("%+.2f%%", c.Delta)
+		}
+		mark := ""
+		if regressed {
+			mark = "REGRESSION"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\tp=%.3f\t%d+%d\t%s\n", c.Key, c.Unit, formatValue(c.Old), formatValue(c.New), delta, c.P, c.OldSamples, c.NewSamples, mark)
+	}
+
+	// A gated benchmark that failed or disappeared since the baseline fails the gate
+	missing := 0
+	for _, key := range benchresult.Missing(baseline.Results, results) {
+		if gated(key) {
+			missing++
+			fmt.Fprintf(tw, "%s\t\t\t\t\t\t\tMISSING\n", key)
+		}
+	}
+	tw.Flush()
+
+	if fewSamples {
+		fmt.Fprintln(w, "\nwarning: fewer than 4 samples per side can never be significant at p<0.05; run with -count=10")
+	}
+	if missing > 0 {
+		fmt.Fprintf(w, "\n%d baseline benchmark(s) missing from the new run\n", missing)
+	}
+	if regressions > 0 {
+		fmt.Fprintf(w, "\n%d regression(s) beyond ns/op +%g%% or allocs/op +%g%%\n", regressions, *timeThreshold, *allocsThreshold)
+	}
+	if missing > 0 || regressions > 0 {
+		return true, nil
+	}
+
+	fmt.Fprintln(w, "\nno regressions")
+	return false, nil
+}
+
+func formatValue(v float64) string {
+	if v == math.Trunc(v) {
+		return fmt.Sprintf("%.0f", v)
+	}
+	return fmt.Sprintf("%.2f", v)
+}
+
+func orUnknown(s string) string {
+	if s == "" {
+		return "unknown version"
+	}
+	return s
+}
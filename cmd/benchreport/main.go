@@ -0,0 +1,64 @@
+// Command benchreport turns `go test -bench` output into a framework × scenario
+// matrix of ns/op, B/op and allocs/op as JSON, CSV or a Markdown table.
+//
+// Usage:
+//
+//	go test -run=^$ -bench=Frameworks -benchmem | go run ./cmd/benchreport -format markdown
+//	go run ./cmd/benchreport -format csv -o results.csv bench.txt
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"tree-framework-benchmark/internal/benchresult"
+)
+
+func main() {
+	format := flag.String("format", "markdown", "output format: json, csv or markdown")
+	output := flag.String("o", "", "write the report to this file instead of stdout")
+	flag.Parse()
+
+	results, err := benchresult.ParseFiles(flag.Args(), os.Stdin)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := report(*output, *format, benchresult.NewMatrices(results)); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// report writes matrices to the file at path, or to stdout when path is empty. The file is
+// closed before report returns, so a write the close reports as failed is not lost silently.
+func report(path, format string, matrices []*benchresult.Matrix) error {
+	if path == "" {
+		return writeReport(os.Stdout, format, matrices)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := writeReport(file, format, matrices); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+func writeReport(w io.Writer, format string, matrices []*benchresult.Matrix) error {
+	switch format {
+	case "json":
+		return benchresult.WriteJSON(w, matrices)
+	case "csv":
+		return benchresult.WriteCSV(w, matrices)
+	case "markdown", "md":
+		return benchresult.WriteMarkdown(w, matrices)
+	default:
+		return fmt.Errorf("unknown format %q (want json, csv or markdown)", format)
+	}
+}
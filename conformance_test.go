@@ -0,0 +1,188 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// response is the golden result every framework must produce for a scenario
+type response struct {
+	status      int
+	contentType string // media type only; parameters such as charset are ignored
+	body        string // compared as normalized JSON when contentType is application/json
+	// anyBody compares only the status, for framework default responses such as 404 pages
+	anyBody bool
+	// panics expects the request to panic out of ServeHTTP instead of producing a response
+	panics bool
+}
+
+// checkConformance serves one scenario request on a fresh instance of fw and
+// reports how the result differs from the scenario's golden response
+func checkConformance(fw frameworkFactory, sc scenario) error {
+	sc = sc.forFramework(fw.name)
+	f := fw.new()
+	sc.routes(f)
+
+	w := httptest.NewRecorder()
+	panicked := serveRecovering(f, w, sc.newRequest())
+
+	var problems []string
+
+	switch {
+	case panicked != sc.want.panics:
+		problems = append(problems, fmt.Sprintf("panic: want %v, got %v", sc.want.panics, panicked))
+	case panicked:
+		// A panicking request has no response to compare
+	default:
+		problems = append(problems, compareResponse(sc.want, w)...)
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%s/%s: %s", fw.name, sc.name, strings.Join(problems, "\n"))
+	}
+	return nil
+}
+
+// serveRecovering serves r and reports whether the handler panicked out of ServeHTTP
+func serveRecovering(h http.Handler, w http.ResponseWriter, r *http.Request) (panicked bool) {
+	defer func() {
+		if recover() != nil {
+			panicked = true
+		}
+	}()
+	h.ServeHTTP(w, r)
+	return false
+}
+
+// recoverHandler recovers panics escaping h the way net/http's connection goroutine does,
+// so requests that crash a framework can still be measured in-process
+type recoverHandler struct {
+	h http.Handler
+}
+
+func (h recoverHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	serveRecovering(h.h, w, r)
+}
+
+// compareResponse reports how a recorded response differs from want
+func compareResponse(want response, w *httptest.ResponseRecorder) []string {
+	var problems []string
+
+	if w.Code != want.status {
+		problems = append(problems, fmt.Sprintf("status: want %d, got %d", want.status, w.Code))
+	}
+
+	if !want.anyBody {
+		mediaType, _, _ := mime.ParseMediaType(w.Header().Get("Content-Type"))
+		if mediaType != want.contentType {
+			problems = append(problems, fmt.Sprintf("Content-Type: want %q, got %q", want.contentType, w.Header().Get("Content-Type")))
+		}
+
+		wantBody, gotBody := want.body, w.Body.String()
+		if want.contentType == "application/json" {
+			wantBody, gotBody = normalizeJSON(wantBody), normalizeJSON(gotBody)
+		}
+		if wantBody != gotBody {
+			problems = append(problems, "body (-want +got):\n"+lineDiff(wantBody, gotBody))
+		}
+	}
+	return problems
+}
+
+// checkAllConformance runs checkConformance for every scenario against every framework
+func checkAllConformance() error {
+	var errs []error
+	for _, sc := range scenarios {
+		for _, fw := range frameworks {
+			if err := checkConformance(fw, sc); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// normalizeJSON re-encodes s with sorted keys and fixed indentation.
+// Invalid JSON is returned unchanged so the diff still shows it.
+func normalizeJSON(s string) string {
+	var v any
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		return s
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return s
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// lineDiff returns a minimal line-based diff of want and got, marking removed lines with "-" and added lines with "+"
+func lineDiff(want, got string) string {
+	a, b := strings.Split(want, "\n"), strings.Split(got, "\n")
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var sb strings.Builder
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			sb.WriteString("  " + a[i] + "\n")
+			i++
+			j++
+		case j < len(b) && (i == len(a) || lcs[i][j+1] >= lcs[i+1][j]):
+			sb.WriteString("+ " + b[j] + "\n")
+			j++
+		default:
+			sb.WriteString("- " + a[i] + "\n")
+			i++
+		}
+	}
+	return sb.String()
+}
+
+// TestConformance verifies every framework returns the golden response for every scenario
+func TestConformance(t *testing.T) {
+	defer silenceStdout(t)()
+
+	for _, fw := range frameworks {
+		for _, sc := range scenarios {
+			t.Run(fw.name+"/"+sc.name, func(t *testing.T) {
+				if err := checkConformance(fw, sc); err != nil {
+					t.Error(err)
+				}
+			})
+		}
+	}
+}
+
+func TestLineDiff(t *testing.T) {
+	got := lineDiff("a\nb\nc", "a\nx\nc")
+	want := "  a\n+ x\n- b\n  c\n"
+	if got != want {
+		t.Errorf("lineDiff = %q, want %q", got, want)
+	}
+}
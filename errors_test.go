@@ -0,0 +1,116 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+)
+
+// errHandlerFailed is returned by the HandlerError scenario's handler
+var errHandlerFailed = errors.New("handler failed")
+
+// handlerPanic is the value failing handlers panic with
+const handlerPanic = "handler panicked"
+
+// registerErrorRoutes adds handlers that fail to the sample routes
+func registerErrorRoutes(f Framework) {
+	registerSampleRoutes(f)
+
+	f.GET("/fail", func(c Context) error {
+		return errHandlerFailed
+	})
+}
+
+// registerPanicRoutes installs the recovery middleware through each framework's middleware
+// facility, then a handler that panics
+func registerPanicRoutes(f Framework) {
+	f.Use(recoveryMiddleware)
+	f.GET("/panic", func(c Context) error {
+		panic(handlerPanic)
+	})
+}
+
+// errorScenarios hit the failure paths any client can trigger cheaply. Frameworks answer them
+// differently, so wantFor records each framework's own status and body.
+func errorScenarios() []scenario {
+	textNotFound := response{status: http.StatusNotFound, contentType: "text/plain", body: "404 page not found\n"}
+	fiberNotFound := func(method, path string) response {
+		return response{status: http.StatusNotFound, contentType: "text/plain", body: "Cannot " + method + " " + path}
+	}
+	fiberNotAllowed := response{status: http.StatusMethodNotAllowed, contentType: "text/plain", body: "Method Not Allowed"}
+	beegoNotFound := response{status: http.StatusNotFound, body: "404"}
+	failed := response{status: http.StatusInternalServerError, contentType: "text/plain", body: errHandlerFailed.Error()}
+	recovered := response{status: http.StatusInternalServerError, contentType: "text/plain", body: handlerPanic}
+
+	return []scenario{
+		{
+			name:   "NotFound",
+			routes: registerErrorRoutes,
+			method: http.MethodGet,
+			target: "/missing",
+			want:   textNotFound,
+			wantFor: map[string]response{
+				"Gin":          {status: http.StatusNotFound, contentType: "text/plain", body: "404 page not found"},
+				"FiberTest":    fiberNotFound("GET", "/missing"),
+				"FiberHandler": fiberNotFound("GET", "/missing"),
+				"FiberAdaptor": fiberNotFound("GET", "/missing"),
+				"Beego":        beegoNotFound,
+			},
+		},
+		{
+			// tree, Gin (without HandleMethodNotAllowed) and Beego answer a wrong method as not found
+			name:   "MethodNotAllowed",
+			routes: registerErrorRoutes,
+			method: http.MethodPost,
+			target: "/user/1",
+			want:   response{status: http.StatusMethodNotAllowed, contentType: "text/plain", body: "Method not allowed\n"},
+			wantFor: map[string]response{
+				"Tree":                 textNotFound,
+				"Gin":                  {status: http.StatusNotFound, contentType: "text/plain", body: "404 page not found"},
+				"FiberTest":            fiberNotAllowed,
+				"FiberHandler":         fiberNotAllowed,
+				"FiberAdaptor":         fiberNotAllowed,
+				"Beego":                beegoNotFound,
+				"StandardHTTPPatterns": {status: http.StatusMethodNotAllowed, contentType: "text/plain", body: "Method Not Allowed\n"},
+			},
+		},
+		{
+			name:   "MalformedJSON",
+			routes: registerErrorRoutes,
+			method: http.MethodPost,
+			target: "/users",
+			body:   []byte(`{"name": "John Doe", "email": `),
+			want:   response{status: http.StatusBadRequest, contentType: "application/json", body: `{"error":"Invalid JSON"}`},
+		},
+		{
+			// tree discards the error a handler returns, and the Gin adapter only records it with
+			// c.Error; both leave the default empty 200 response
+			name:   "HandlerError",
+			routes: registerErrorRoutes,
+			method: http.MethodGet,
+			target: "/fail",
+			want:   response{status: http.StatusInternalServerError, contentType: "text/plain", body: errHandlerFailed.Error() + "\n"},
+			wantFor: map[string]response{
+				"Tree":         {status: http.StatusOK},
+				"Gin":          {status: http.StatusOK},
+				"FiberTest":    failed,
+				"FiberHandler": failed,
+				"FiberAdaptor": failed,
+				"Beego":        {status: http.StatusInternalServerError, body: errHandlerFailed.Error()},
+			},
+		},
+		{
+			// tree always runs the handler after its middlewares return, so no middleware can
+			// recover a handler panic and it escapes ServeHTTP. Beego's router recovers it before
+			// the filter chain sees it and, in production mode, answers an empty 500.
+			name:   "HandlerPanic",
+			routes: registerPanicRoutes,
+			method: http.MethodGet,
+			target: "/panic",
+			want:   recovered,
+			wantFor: map[string]response{
+				"Tree":  {panics: true},
+				"Beego": {status: http.StatusInternalServerError},
+			},
+		},
+	}
+}
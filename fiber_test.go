@@ -1,18 +1,61 @@
 package main
 
 import (
-	"bytes"
-	"encoding/json"
+	"bufio"
+	"context"
+	"io"
+	"log"
+	"mime/multipart"
+	"net"
 	"net/http"
-	"net/http/httptest"
-	"strconv"
-	"testing"
+	"regexp"
+	"sync"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/valyala/fasthttp"
 )
 
-// setupFiberApp creates a new Fiber app instance for benchmarking
-func setupFiberApp() *fiber.App {
+// fiberMode selects how requests reach the Fiber app. Fiber is built on fasthttp and is not an
+// http.Handler, so each mode pays a different transport cost on top of Fiber's own routing.
+type fiberMode int
+
+const (
+	// fiberModeTest goes through app.Test(), which serializes every request and response
+	// as HTTP/1.1 over an in-memory connection
+	fiberModeTest fiberMode = iota
+	// fiberModeHandler calls app.Handler() directly on a reused fasthttp.RequestCtx,
+	// isolating routing and handler cost from transport cost
+	fiberModeHandler
+	// fiberModeAdaptor exposes the app as an http.Handler through the official adaptor,
+	// which converts every net/http request into a fresh fasthttp request
+	fiberModeAdaptor
+)
+
+// fiberFramework adapts fiber.App to the Framework interface
+type fiberFramework struct {
+	app  *fiber.App
+	mode fiberMode
+
+	handler    fasthttp.RequestHandler // fiberModeHandler
+	ctxPool    sync.Pool               // reused *fasthttp.RequestCtx for fiberModeHandler
+	http       http.HandlerFunc        // fiberModeAdaptor
+	httpServer *http.Server            // socket server for fiberModeAdaptor
+}
+
+func newFiberTestFramework() Framework {
+	return newFiberFrameworkMode(fiberModeTest)
+}
+
+func newFiberHandlerFramework() Framework {
+	return newFiberFrameworkMode(fiberModeHandler)
+}
+
+func newFiberAdaptorFramework() Framework {
+	return newFiberFrameworkMode(fiberModeAdaptor)
+}
+
+func newFiberFrameworkMode(mode fiberMode) *fiberFramework {
 	// Create Fiber app with minimal config for better performance
 	app := fiber.New(fiber.Config{
 		Prefork:                   false,
@@ -20,271 +63,239 @@ func setupFiberApp() *fiber.App {
 		StrictRouting:             true,
 		ServerHeader:              "",
 		AppName:                   "",
-		DisableKeepalive:          true,
+		DisableKeepalive:          false, // keep-alive matters once the app is served on a real socket
 		DisableDefaultDate:        true,
 		DisableDefaultContentType: true,
 		DisableHeaderNormalizing:  true,
 		DisableStartupMessage:     true,
 	})
 
-	// Simple GET handler
-	app.Get("/", func(c *fiber.Ctx) error {
-		return c.Status(http.StatusOK).SendString("Hello, Fiber Framework!")
-	})
-
-	// JSON response handler
-	app.Get("/user/:id", func(c *fiber.Ctx) error {
-		id := c.Params("id")
-		user := User{
-			ID:    1,
-			Name:  "John Doe",
-			Email: "john@example.com",
-		}
-
-		response := fiber.Map{
-			"id":   id,
-			"user": user,
-		}
-
-		return c.Status(http.StatusOK).JSON(response)
-	})
-
-	// POST handler with JSON body
-	app.Post("/users", func(c *fiber.Ctx) error {
-		var user User
-		if err := c.BodyParser(&user); err != nil {
-			return c.Status(http.StatusBadRequest).JSON(fiber.Map{
-				"error": "Invalid JSON",
-			})
-		}
-
-		user.ID = 123
-		response := fiber.Map{
-			"id":    user.ID,
-			"name":  user.Name,
-			"email": user.Email,
-		}
-
-		return c.Status(http.StatusCreated).JSON(response)
-	})
-
-	// Multiple route parameters
-	app.Get("/users/:id/posts/:postId", func(c *fiber.Ctx) error {
-		userID := c.Params("id")
-		postID := c.Params("postId")
-
-		response := fiber.Map{
-			"userId": userID,
-			"postId": postID,
-		}
+	f := &fiberFramework{app: app, mode: mode}
+	f.ctxPool.New = func() any {
+		fctx := &fasthttp.RequestCtx{}
+		// fasthttp's default logger would print every refused directory index of the static benchmarks
+		fctx.Init(&fasthttp.Request{}, nil, log.New(io.Discard, "", 0))
+		return fctx
+	}
+	return f
+}
 
-		return c.Status(http.StatusOK).JSON(response)
-	})
+func (f *fiberFramework) GET(path string, h HandlerFunc) {
+	f.Handle(http.MethodGet, path, h)
+}
 
-	// Query parameters
-	app.Get("/search", func(c *fiber.Ctx) error {
-		query := c.Query("q")
-		limit := c.Query("limit")
+func (f *fiberFramework) POST(path string, h HandlerFunc) {
+	f.Handle(http.MethodPost, path, h)
+}
 
-		response := fiber.Map{
-			"query": query,
-			"limit": limit,
-		}
+func (f *fiberFramework) Handle(method, path string, h HandlerFunc) {
+	path, patterns := compileRegexRoute(path)
+	f.app.Add(method, path, fiberHandler(h, patterns))
+}
 
-		return c.Status(http.StatusOK).JSON(response)
+func (f *fiberFramework) Use(m MiddlewareFunc) {
+	f.app.Use(func(c *fiber.Ctx) error {
+		return m(fiberContext{c: c}, c.Next)
 	})
-
-	return app
 }
 
-// Fiber Benchmark simple GET request
-func BenchmarkFiberSimpleGET(b *testing.B) {
-	app := setupFiberApp()
-	req := httptest.NewRequest("GET", "/", nil)
-
-	b.ResetTimer()
-	b.ReportAllocs()
+func (f *fiberFramework) Static(prefix, root string) {
+	f.app.Static(prefix, root, fiber.Static{ByteRange: true})
+}
 
-	for i := 0; i < b.N; i++ {
-		resp, err := app.Test(req, -1) // No timeout for benchmark consistency
-		if err != nil {
-			b.Fatal(err)
+func (f *fiberFramework) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch f.mode {
+	case fiberModeHandler:
+		f.serveRequestCtx(w, r)
+	case fiberModeAdaptor:
+		// Fiber builds its router on first use, so the handler is created after all routes are registered
+		if f.http == nil {
+			f.http = adaptor.FiberApp(f.app)
 		}
-		resp.Body.Close()
+		f.http(w, r)
+	default:
+		f.serveTest(w, r)
 	}
 }
 
-// Fiber Benchmark GET request with route parameter
-func BenchmarkFiberGetWithParam(b *testing.B) {
-	app := setupFiberApp()
-	req := httptest.NewRequest("GET", "/user/123", nil)
-
-	b.ResetTimer()
-	b.ReportAllocs()
-
-	for i := 0; i < b.N; i++ {
-		resp, _ := app.Test(req, 1)
-		resp.Body.Close()
+// Serve serves the app on ln the way its mode reaches it: behind net/http in adaptor mode,
+// natively through fasthttp otherwise
+func (f *fiberFramework) Serve(ln net.Listener) error {
+	if f.mode == fiberModeAdaptor {
+		f.httpServer = &http.Server{Handler: f}
+		return f.httpServer.Serve(ln)
 	}
+	return f.app.Listener(ln)
 }
 
-// Fiber Benchmark GET request with multiple route parameters
-func BenchmarkFiberGetWithMultipleParams(b *testing.B) {
-	app := setupFiberApp()
-	req := httptest.NewRequest("GET", "/users/123/posts/456", nil)
+func (f *fiberFramework) Shutdown() error {
+	if f.httpServer != nil {
+		return f.httpServer.Shutdown(context.Background())
+	}
+	return f.app.Shutdown()
+}
 
-	b.ResetTimer()
-	b.ReportAllocs()
+func (f *fiberFramework) serveTest(w http.ResponseWriter, r *http.Request) {
+	resp, err := f.app.Test(r, -1) // No timeout for benchmark consistency
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer resp.Body.Close()
 
-	for i := 0; i < b.N; i++ {
-		resp, _ := app.Test(req, -1)
-		resp.Body.Close()
+	for key, values := range resp.Header {
+		w.Header()[key] = values
 	}
+	w.WriteHeader(resp.StatusCode)
+	io.Copy(w, resp.Body)
 }
 
-// Fiber Benchmark GET request with query parameters
-func BenchmarkFiberGetWithQueryParams(b *testing.B) {
-	app := setupFiberApp()
-	req := httptest.NewRequest("GET", "/search?q=golang&limit=10", nil)
+func (f *fiberFramework) serveRequestCtx(w http.ResponseWriter, r *http.Request) {
+	if f.handler == nil {
+		f.handler = f.app.Handler()
+	}
 
-	b.ResetTimer()
-	b.ReportAllocs()
+	fctx := f.ctxPool.Get().(*fasthttp.RequestCtx)
+	defer f.ctxPool.Put(fctx)
 
-	for i := 0; i < b.N; i++ {
-		resp, _ := app.Test(req, -1)
-		resp.Body.Close()
+	fctx.Response.Reset()
+	if err := copyFasthttpRequest(&fctx.Request, r); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
 	}
-}
 
-// Fiber Benchmark POST request with JSON payload
-func BenchmarkFiberPostWithJSON(b *testing.B) {
-	app := setupFiberApp()
+	f.handler(fctx)
 
-	user := User{
-		Name:  "Test User",
-		Email: "test@example.com",
-	}
+	fctx.Response.Header.VisitAll(func(key, value []byte) {
+		w.Header().Add(string(key), string(value))
+	})
+	w.WriteHeader(fctx.Response.StatusCode())
+	w.Write(fctx.Response.Body())
+}
 
-	jsonData, _ := json.Marshal(user)
+// prepare converts r to a fasthttp request once and returns a func serving it again on its own
+// RequestCtx, so the benchmark loop of fiberModeHandler pays routing and handler cost only.
+// It returns nil in the other modes, whose conversion is part of what they measure.
+func (f *fiberFramework) prepare(r *http.Request) (func(), error) {
+	if f.mode != fiberModeHandler {
+		return nil, nil
+	}
+	if f.handler == nil {
+		f.handler = f.app.Handler()
+	}
 
-	b.ResetTimer()
-	b.ReportAllocs()
+	var prepared fasthttp.Request
+	if err := copyFasthttpRequest(&prepared, r); err != nil {
+		return nil, err
+	}
+	fctx := f.ctxPool.New().(*fasthttp.RequestCtx)
+	prepared.CopyTo(&fctx.Request)
+	hasBody := len(prepared.Body()) > 0
+
+	return func() {
+		if hasBody {
+			// fasthttp caches parsed form values on the request; restore it so every
+			// iteration parses the body again, as a fresh request would
+			prepared.CopyTo(&fctx.Request)
+		}
+		fctx.Response.Reset()
+		f.handler(fctx)
+	}, nil
+}
 
-	for i := 0; i < b.N; i++ {
-		req := httptest.NewRequest("POST", "/users", bytes.NewBuffer(jsonData))
-		req.Header.Set("Content-Type", "application/json")
-		resp, _ := app.Test(req, -1)
-		resp.Body.Close()
+// copyFasthttpRequest resets dst and fills it with the method, URI, host, headers and body of r
+func copyFasthttpRequest(dst *fasthttp.Request, r *http.Request) error {
+	dst.Reset()
+	dst.Header.SetMethod(r.Method)
+	dst.SetRequestURI(r.URL.RequestURI())
+	dst.Header.SetHost(r.Host)
+	for key, values := range r.Header {
+		for _, value := range values {
+			dst.Header.Add(key, value)
+		}
+	}
+	if r.Body != nil {
+		if _, err := io.Copy(dst.BodyWriter(), r.Body); err != nil {
+			return err
+		}
 	}
+	return nil
 }
 
-// Fiber Benchmark routing performance with different number of routes
-func BenchmarkFiberRouting10Routes(b *testing.B) {
-	benchmarkFiberRouting(b, 10)
+func fiberHandler(h HandlerFunc, patterns []*regexp.Regexp) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		return h(fiberContext{c: c, patterns: patterns})
+	}
 }
 
-func BenchmarkFiberRouting100Routes(b *testing.B) {
-	benchmarkFiberRouting(b, 100)
+// fiberContext implements Context on top of *fiber.Ctx
+type fiberContext struct {
+	c        *fiber.Ctx
+	patterns []*regexp.Regexp
 }
 
-func BenchmarkFiberRouting1000Routes(b *testing.B) {
-	benchmarkFiberRouting(b, 1000)
+func (c fiberContext) Param(name string) string {
+	return c.c.Params(name)
 }
 
-func benchmarkFiberRouting(b *testing.B, numRoutes int) {
-	app := fiber.New(fiber.Config{
-		Prefork:       false,
-		CaseSensitive: true,
-		StrictRouting: true,
-		ServerHeader:  "",
-		AppName:       "",
-	})
+func (c fiberContext) RegexParam(index int) (string, error) {
+	return regexParam(c, c.patterns, index)
+}
 
-	// Create multiple routes
-	for i := 0; i < numRoutes; i++ {
-		route := "/route" + strconv.Itoa(i)
-		routeIndex := i // Capture loop variable
-		app.Get(route, func(c *fiber.Ctx) error {
-			return c.Status(http.StatusOK).SendString("Route " + strconv.Itoa(routeIndex))
-		})
-	}
+func (c fiberContext) Query(name string) string {
+	return c.c.Query(name)
+}
 
-	// Test the last route (worst case scenario)
-	req := httptest.NewRequest("GET", "/route"+strconv.Itoa(numRoutes-1), nil)
+func (c fiberContext) BindJSON(v any) error {
+	return c.c.BodyParser(v)
+}
 
-	b.ResetTimer()
-	b.ReportAllocs()
+func (c fiberContext) BindForm(v any) error {
+	return c.c.BodyParser(v)
+}
 
-	for i := 0; i < b.N; i++ {
-		resp, _ := app.Test(req, -1)
-		resp.Body.Close()
-	}
+func (c fiberContext) BindQuery(v any) error {
+	return c.c.QueryParser(v)
 }
 
-// Fiber Benchmark concurrent requests
-func BenchmarkFiberConcurrentRequests(b *testing.B) {
-	app := setupFiberApp()
-	req := httptest.NewRequest("GET", "/", nil)
+func (c fiberContext) FormFile(name string) (*multipart.FileHeader, error) {
+	return c.c.FormFile(name)
+}
 
-	b.ResetTimer()
-	b.ReportAllocs()
+func (c fiberContext) JSON(code int, body H) error {
+	return c.c.Status(code).JSON(body)
+}
 
-	b.RunParallel(func(pb *testing.PB) {
-		for pb.Next() {
-			resp, _ := app.Test(req, -1)
-			resp.Body.Close()
-		}
-	})
+func (c fiberContext) String(code int, s string) error {
+	c.c.Set(fiber.HeaderContentType, fiber.MIMETextPlainCharsetUTF8)
+	return c.c.Status(code).SendString(s)
 }
 
-// Fiber Benchmark memory allocations for different payload sizes
-func BenchmarkFiberSmallPayload(b *testing.B) {
-	benchmarkFiberPayloadSize(b, 100)
+func (c fiberContext) Method() string {
+	return c.c.Method()
 }
 
-func BenchmarkFiberMediumPayload(b *testing.B) {
-	benchmarkFiberPayloadSize(b, 1024)
+func (c fiberContext) Path() string {
+	return c.c.Path()
 }
 
-func BenchmarkFiberLargePayload(b *testing.B) {
-	benchmarkFiberPayloadSize(b, 10240)
+// Header looks name up in canonical form: with DisableHeaderNormalizing, fasthttp keeps header keys
+// as sent, and net/http clients send canonical keys
+func (c fiberContext) Header(name string) string {
+	return c.c.Get(http.CanonicalHeaderKey(name))
 }
 
-func benchmarkFiberPayloadSize(b *testing.B, size int) {
-	app := fiber.New(fiber.Config{
-		Prefork:       false,
-		CaseSensitive: true,
-		StrictRouting: true,
-		ServerHeader:  "",
-		AppName:       "",
-	})
+func (c fiberContext) SetHeader(name, value string) {
+	c.c.Set(name, value)
+}
 
-	app.Post("/data", func(c *fiber.Ctx) error {
-		// Read and echo back the data
-		var data map[string]interface{}
-		if err := c.BodyParser(&data); err != nil {
-			return c.Status(http.StatusBadRequest).JSON(fiber.Map{
-				"error": "Invalid JSON",
-			})
-		}
-		return c.Status(http.StatusOK).JSON(data)
+// Stream hands write to fasthttp's body stream writer, which runs after the handler returns,
+// so errors from write cannot be reported
+func (c fiberContext) Stream(code int, contentType string, write StreamFunc) error {
+	c.c.Set(fiber.HeaderContentType, contentType)
+	c.c.Status(code)
+	c.c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		write(w, w.Flush)
 	})
-
-	// Create payload of specified size
-	payload := make(map[string]string)
-	for i := 0; i < size/10; i++ { // Approximate size control
-		payload["key"+strconv.Itoa(i)] = "value" + strconv.Itoa(i)
-	}
-
-	jsonData, _ := json.Marshal(payload)
-
-	b.ResetTimer()
-	b.ReportAllocs()
-
-	for i := 0; i < b.N; i++ {
-		req := httptest.NewRequest("POST", "/data", bytes.NewBuffer(jsonData))
-		req.Header.Set("Content-Type", "application/json")
-		resp, _ := app.Test(req, -1)
-		resp.Body.Close()
-	}
+	return nil
 }
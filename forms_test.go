@@ -0,0 +1,210 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+)
+
+// formFields is bound from urlencoded and multipart bodies by its `form` tags, and from the
+// query string by its `query` tags. Gin binds queries with `form` tags, hence the same names.
+// Tags is omitted when empty because Beego binds an absent slice as empty rather than nil.
+type formFields struct {
+	Name    string   `form:"name" query:"name" json:"name"`
+	Email   string   `form:"email" query:"email" json:"email"`
+	Age     int      `form:"age" query:"age" json:"age"`
+	Score   float64  `form:"score" query:"score" json:"score"`
+	Active  bool     `form:"active" query:"active" json:"active"`
+	Tags    []string `form:"tags" query:"tags" json:"tags,omitempty"`
+	City    string   `form:"city" query:"city" json:"city"`
+	Country string   `form:"country" query:"country" json:"country"`
+	Phone   string   `form:"phone" query:"phone" json:"phone"`
+	Bio     string   `form:"bio" query:"bio" json:"bio"`
+}
+
+// formValues fills all ten fields of formFields; tags is repeated to exercise slice binding
+var formValues = url.Values{
+	"name":    {"John Doe"},
+	"email":   {"john@example.com"},
+	"age":     {"30"},
+	"score":   {"97.5"},
+	"active":  {"true"},
+	"tags":    {"go", "http"},
+	"city":    {"Bucharest"},
+	"country": {"RO"},
+	"phone":   {"+40 721 000 000"},
+	"bio":     {"Writes benchmarks & reads flame graphs"},
+}
+
+// formBound is what every framework must bind from formValues
+var formBound = formFields{
+	Name: "John Doe", Email: "john@example.com", Age: 30, Score: 97.5, Active: true,
+	Tags: []string{"go", "http"}, City: "Bucharest", Country: "RO",
+	Phone: "+40 721 000 000", Bio: "Writes benchmarks & reads flame graphs",
+}
+
+// formBoundary is fixed so multipart bodies are byte-for-byte identical across runs
+const formBoundary = "tree-framework-benchmark-boundary"
+
+// formFile is a file part of a multipart body
+type formFile struct {
+	name string
+	data []byte
+}
+
+// multipartBody encodes values and an optional file part as multipart/form-data
+func multipartBody(values url.Values, file *formFile) ([]byte, string) {
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	if err := w.SetBoundary(formBoundary); err != nil {
+		panic(err)
+	}
+
+	// Field order is fixed by formFieldOrder since url.Values is a map
+	for _, key := range formFieldOrder {
+		for _, value := range values[key] {
+			if err := w.WriteField(key, value); err != nil {
+				panic(err)
+			}
+		}
+	}
+	if file != nil {
+		part, err := w.CreateFormFile("file", file.name)
+		if err != nil {
+			panic(err)
+		}
+		part.Write(file.data)
+	}
+	if err := w.Close(); err != nil {
+		panic(err)
+	}
+	return buf.Bytes(), w.FormDataContentType()
+}
+
+var formFieldOrder = []string{"name", "email", "age", "score", "active", "tags", "city", "country", "phone", "bio"}
+
+// formFileContent is deterministic file content of the given size
+func formFileContent(size int) []byte {
+	return bytes.Repeat([]byte("0123456789abcdef"), size/16+1)[:size]
+}
+
+// registerFormRoutes echoes what each framework bound, so golden responses compare the bound values
+func registerFormRoutes(f Framework) {
+	f.POST("/form", func(c Context) error {
+		var form formFields
+		if err := c.BindForm(&form); err != nil {
+			return c.JSON(http.StatusBadRequest, H{"error": err.Error()})
+		}
+		return c.JSON(http.StatusOK, H{"form": form})
+	})
+
+	f.POST("/upload", func(c Context) error {
+		var form formFields
+		if err := c.BindForm(&form); err != nil {
+			return c.JSON(http.StatusBadRequest, H{"error": err.Error()})
+		}
+
+		header, err := c.FormFile("file")
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, H{"error": err.Error()})
+		}
+		file, err := header.Open()
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		hash := sha256.New()
+		size, err := io.Copy(hash, file)
+		if err != nil {
+			return err
+		}
+		return c.JSON(http.StatusOK, H{
+			"form": form,
+			"file": H{"name": header.Filename, "size": size, "sha256": hex.EncodeToString(hash.Sum(nil))},
+		})
+	})
+
+	f.GET("/bind", func(c Context) error {
+		var query formFields
+		if err := c.BindQuery(&query); err != nil {
+			return c.JSON(http.StatusBadRequest, H{"error": err.Error()})
+		}
+		return c.JSON(http.StatusOK, H{"query": query})
+	})
+}
+
+// formScenarios bind urlencoded and multipart bodies and query strings of increasing size
+func formScenarios() []scenario {
+	oneField := url.Values{"name": formValues["name"]}
+	multipart1, multipart1Type := multipartBody(oneField, nil)
+	multipart10, multipart10Type := multipartBody(formValues, nil)
+
+	scenarios := []scenario{
+		{
+			name:        "FormURLEncoded",
+			routes:      registerFormRoutes,
+			method:      http.MethodPost,
+			target:      "/form",
+			body:        []byte(formValues.Encode()),
+			contentType: "application/x-www-form-urlencoded",
+			want:        response{status: http.StatusOK, contentType: "application/json", body: string(mustMarshal(H{"form": formBound}))},
+		},
+		{
+			name:        "Multipart1Field",
+			routes:      registerFormRoutes,
+			method:      http.MethodPost,
+			target:      "/form",
+			body:        multipart1,
+			contentType: multipart1Type,
+			want: response{status: http.StatusOK, contentType: "application/json",
+				body: string(mustMarshal(H{"form": formFields{Name: formBound.Name}}))},
+		},
+		{
+			name:        "Multipart10Fields",
+			routes:      registerFormRoutes,
+			method:      http.MethodPost,
+			target:      "/form",
+			body:        multipart10,
+			contentType: multipart10Type,
+			want:        response{status: http.StatusOK, contentType: "application/json", body: string(mustMarshal(H{"form": formBound}))},
+		},
+		{
+			name:   "QueryBind",
+			routes: registerFormRoutes,
+			method: http.MethodGet,
+			target: "/bind?" + formValues.Encode(),
+			want:   response{status: http.StatusOK, contentType: "application/json", body: string(mustMarshal(H{"query": formBound}))},
+		},
+	}
+
+	for _, size := range []struct {
+		label string
+		size  int
+	}{
+		{"1KB", 1 << 10},
+		{"1MB", 1 << 20},
+	} {
+		data := formFileContent(size.size)
+		body, contentType := multipartBody(oneField, &formFile{name: "upload.bin", data: data})
+		sum := sha256.Sum256(data)
+
+		scenarios = append(scenarios, scenario{
+			name:        "MultipartFile" + size.label,
+			routes:      registerFormRoutes,
+			method:      http.MethodPost,
+			target:      "/upload",
+			body:        body,
+			contentType: contentType,
+			want: response{status: http.StatusOK, contentType: "application/json", body: string(mustMarshal(H{
+				"form": formFields{Name: formBound.Name},
+				"file": H{"name": "upload.bin", "size": size.size, "sha256": hex.EncodeToString(sum[:])},
+			}))},
+		})
+	}
+	return scenarios
+}
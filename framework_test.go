@@ -0,0 +1,150 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// H is the JSON object type handlers pass to Context.JSON
+type H map[string]any
+
+// Context is the per-request API every framework adapter exposes to the shared scenario handlers
+type Context interface {
+	// Param returns the value of a `:name` route parameter
+	Param(name string) string
+	// RegexParam returns the value of the index-th (1-based) `:|pattern|` route segment,
+	// or errRegexNotRespected if it does not match its pattern
+	RegexParam(index int) (string, error)
+	// Query returns the first value of a query string parameter
+	Query(name string) string
+	// BindJSON decodes the JSON request body into v
+	BindJSON(v any) error
+	// BindForm decodes an application/x-www-form-urlencoded or multipart/form-data body
+	// into the struct v points to, matching fields by their `form` tag
+	BindForm(v any) error
+	// BindQuery decodes the query string into the struct v points to, matching fields by their
+	// `query` tag, or `form` for frameworks that bind queries with it
+	BindQuery(v any) error
+	// FormFile returns the header of the first file uploaded as name in a multipart body
+	FormFile(name string) (*multipart.FileHeader, error)
+	// JSON writes body as a JSON response with the given status code
+	JSON(code int, body H) error
+	// String writes s as a plain text response with the given status code
+	String(code int, s string) error
+	// Method returns the request method
+	Method() string
+	// Path returns the request URL path
+	Path() string
+	// Header returns the first value of a request header
+	Header(name string) string
+	// SetHeader sets a response header
+	SetHeader(name, value string)
+	// Stream writes the status code and content type, then lets write produce the body
+	// directly on the connection, flushing whenever it needs to
+	Stream(code int, contentType string, write StreamFunc) error
+}
+
+// HandlerFunc is a framework-neutral route handler
+type HandlerFunc func(c Context) error
+
+// StreamFunc writes a response body to w. flush pushes what was written so far to the client.
+type StreamFunc func(w io.Writer, flush func() error) error
+
+// MiddlewareFunc is a framework-neutral middleware. It calls next to continue the chain,
+// or writes a response and returns without calling it to stop the request.
+type MiddlewareFunc func(c Context, next func() error) error
+
+// Framework registers framework-neutral handlers on one web framework and serves them in-process.
+// Route paths use the `:name` parameter syntax and tree's `:|pattern|` regex segments;
+// adapters translate them where needed.
+type Framework interface {
+	GET(path string, h HandlerFunc)
+	POST(path string, h HandlerFunc)
+	// Handle registers h for any of GET, POST, PUT, PATCH and DELETE
+	Handle(method, path string, h HandlerFunc)
+	// Use appends m to the chain run before every route. It must be called before routes are registered.
+	Use(m MiddlewareFunc)
+	// Static serves the files directly under root at prefix with the framework's own file serving.
+	// Directory listings are never generated.
+	Static(prefix, root string)
+	http.Handler
+}
+
+// frameworkFactory builds a fresh Framework with no routes registered
+type frameworkFactory struct {
+	name string
+	new  func() Framework
+	// inProcessOnly marks variants that only differ from another entry in how in-process
+	// requests reach them; they are skipped by the real-socket load benchmarks
+	inProcessOnly bool
+}
+
+// frameworks lists every adapter, in the order results are reported
+var frameworks = []frameworkFactory{
+	{name: "Tree", new: newTreeFramework},
+	{name: "Gin", new: newGinFramework},
+	{name: "FiberTest", new: newFiberTestFramework, inProcessOnly: true},
+	{name: "FiberHandler", new: newFiberHandlerFramework},
+	{name: "FiberAdaptor", new: newFiberAdaptorFramework},
+	{name: "Beego", new: newBeegoFramework},
+	{name: "StandardHTTP", new: newStandardHTTPFramework},
+	{name: "StandardHTTPPatterns", new: newStandardHTTPPatternsFramework},
+}
+
+var (
+	// Same messages as tree.ErrRegexParamDoesntExist and tree.ErrRegexNotRespected so response bodies match
+	errRegexParamDoesntExist = errors.New("regex parameter does not exist")
+	errRegexNotRespected     = errors.New("regex not respected")
+)
+
+// compileRegexRoute rewrites tree-style `:|pattern|` segments to plain `:re1`, `:re2`, ... parameters
+// for frameworks without route-level regex, and returns their precompiled patterns in order.
+// Patterns must not contain '/', as in tree.
+func compileRegexRoute(path string) (string, []*regexp.Regexp) {
+	if !strings.Contains(path, ":|") {
+		return path, nil
+	}
+
+	var patterns []*regexp.Regexp
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":|") && strings.HasSuffix(segment, "|") && len(segment) > 3 {
+			patterns = append(patterns, regexp.MustCompile(segment[2:len(segment)-1]))
+			segments[i] = ":re" + strconv.Itoa(len(patterns))
+		}
+	}
+	return strings.Join(segments, "/"), patterns
+}
+
+// regexParam implements Context.RegexParam for adapters that registered their route with compileRegexRoute
+func regexParam(c Context, patterns []*regexp.Regexp, index int) (string, error) {
+	if index < 1 || index > len(patterns) {
+		return "", errRegexParamDoesntExist
+	}
+
+	value := c.Param("re" + strconv.Itoa(index))
+	if !patterns[index-1].MatchString(value) {
+		return "", errRegexNotRespected
+	}
+	return value, nil
+}
+
+func TestCompileRegexRoute(t *testing.T) {
+	path, patterns := compileRegexRoute(usernameEmailRoute)
+	if path != "/user/:re1/email/:re2" {
+		t.Errorf("path = %q, want /user/:re1/email/:re2", path)
+	}
+	if len(patterns) != 2 || !patterns[0].MatchString("john_doe") || patterns[0].MatchString("ab") || !patterns[1].MatchString("john@example.com") {
+		t.Errorf("unexpected patterns %v", patterns)
+	}
+
+	if path, patterns := compileRegexRoute("/users/:id"); path != "/users/:id" || patterns != nil {
+		t.Errorf("plain route rewritten to %q with %v", path, patterns)
+	}
+}
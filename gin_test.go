@@ -1,256 +1,134 @@
 package main
 
 import (
-	"bytes"
-	"encoding/json"
+	"mime/multipart"
 	"net/http"
-	"net/http/httptest"
-	"strconv"
-	"testing"
+	"regexp"
 
 	"github.com/gin-gonic/gin"
 )
 
-// setupGinApp creates a new Gin app instance for benchmarking
-func setupGinApp() *gin.Engine {
+// ginFramework adapts gin.Engine to the Framework interface
+type ginFramework struct {
+	engine *gin.Engine
+}
+
+func newGinFramework() Framework {
 	// Set Gin to release mode to avoid debug output affecting benchmarks
 	gin.SetMode(gin.ReleaseMode)
 
-	app := gin.New()
-
-	// Simple GET handler
-	app.GET("/", func(c *gin.Context) {
-		c.String(http.StatusOK, "Hello, Gin Framework!")
-	})
-
-	// JSON response handler
-	app.GET("/user/:id", func(c *gin.Context) {
-		id := c.Param("id")
-		user := User{
-			ID:    1,
-			Name:  "John Doe",
-			Email: "john@example.com",
-		}
-		c.JSON(http.StatusOK, gin.H{
-			"id":   id,
-			"user": user,
-		})
-	})
-
-	// POST handler with JSON body
-	app.POST("/users", func(c *gin.Context) {
-		var user User
-		if err := c.ShouldBindJSON(&user); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON"})
-			return
-		}
-
-		user.ID = 123
-		c.JSON(http.StatusCreated, gin.H{
-			"id":    user.ID,
-			"name":  user.Name,
-			"email": user.Email,
-		})
-	})
+	return &ginFramework{engine: gin.New()}
+}
 
-	// Multiple route parameters
-	app.GET("/users/:id/posts/:postId", func(c *gin.Context) {
-		userID := c.Param("id")
-		postID := c.Param("postId")
+func (f *ginFramework) GET(path string, h HandlerFunc) {
+	f.Handle(http.MethodGet, path, h)
+}
 
-		c.JSON(http.StatusOK, gin.H{
-			"userId": userID,
-			"postId": postID,
-		})
-	})
+func (f *ginFramework) POST(path string, h HandlerFunc) {
+	f.Handle(http.MethodPost, path, h)
+}
 
-	// Query parameters
-	app.GET("/search", func(c *gin.Context) {
-		query := c.Query("q")
-		limit := c.Query("limit")
+func (f *ginFramework) Handle(method, path string, h HandlerFunc) {
+	path, patterns := compileRegexRoute(path)
+	f.engine.Handle(method, path, ginHandler(h, patterns))
+}
 
-		c.JSON(http.StatusOK, gin.H{
-			"query": query,
-			"limit": limit,
+func (f *ginFramework) Use(m MiddlewareFunc) {
+	f.engine.Use(func(c *gin.Context) {
+		called := false
+		err := m(ginContext{c: c}, func() error {
+			called = true
+			c.Next()
+			return nil
 		})
+		if err != nil {
+			c.Error(err)
+		}
+		// Gin runs the next handler anyway unless the chain is aborted
+		if !called {
+			c.Abort()
+		}
 	})
-
-	return app
 }
 
-// Gin Benchmark simple GET request
-func BenchmarkGinSimpleGET(b *testing.B) {
-	app := setupGinApp()
-	req := httptest.NewRequest("GET", "/", nil)
-
-	b.ResetTimer()
-	b.ReportAllocs()
-
-	for i := 0; i < b.N; i++ {
-		w := httptest.NewRecorder()
-		app.ServeHTTP(w, req)
-	}
+func (f *ginFramework) Static(prefix, root string) {
+	f.engine.Static(prefix, root)
 }
 
-// Gin Benchmark GET request with route parameter
-func BenchmarkGinGetWithParam(b *testing.B) {
-	app := setupGinApp()
-	req := httptest.NewRequest("GET", "/user/123", nil)
-
-	b.ResetTimer()
-	b.ReportAllocs()
-
-	for i := 0; i < b.N; i++ {
-		w := httptest.NewRecorder()
-		app.ServeHTTP(w, req)
-	}
+func (f *ginFramework) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.engine.ServeHTTP(w, r)
 }
 
-// Gin Benchmark GET request with multiple route parameters
-func BenchmarkGinGetWithMultipleParams(b *testing.B) {
-	app := setupGinApp()
-	req := httptest.NewRequest("GET", "/users/123/posts/456", nil)
-
-	b.ResetTimer()
-	b.ReportAllocs()
-
-	for i := 0; i < b.N; i++ {
-		w := httptest.NewRecorder()
-		app.ServeHTTP(w, req)
+func ginHandler(h HandlerFunc, patterns []*regexp.Regexp) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := h(ginContext{c: c, patterns: patterns}); err != nil {
+			c.Error(err)
+		}
 	}
 }
 
-// Gin Benchmark GET request with query parameters
-func BenchmarkGinGetWithQueryParams(b *testing.B) {
-	app := setupGinApp()
-	req := httptest.NewRequest("GET", "/search?q=golang&limit=10", nil)
-
-	b.ResetTimer()
-	b.ReportAllocs()
-
-	for i := 0; i < b.N; i++ {
-		w := httptest.NewRecorder()
-		app.ServeHTTP(w, req)
-	}
+// ginContext implements Context on top of *gin.Context
+type ginContext struct {
+	c        *gin.Context
+	patterns []*regexp.Regexp
 }
 
-// Gin Benchmark POST request with JSON payload
-func BenchmarkGinPostWithJSON(b *testing.B) {
-	app := setupGinApp()
-
-	user := User{
-		Name:  "Test User",
-		Email: "test@example.com",
-	}
-
-	jsonData, _ := json.Marshal(user)
-
-	b.ResetTimer()
-	b.ReportAllocs()
-
-	for i := 0; i < b.N; i++ {
-		req := httptest.NewRequest("POST", "/users", bytes.NewBuffer(jsonData))
-		req.Header.Set("Content-Type", "application/json")
-		w := httptest.NewRecorder()
-		app.ServeHTTP(w, req)
-	}
+func (c ginContext) Param(name string) string {
+	return c.c.Param(name)
 }
 
-// Gin Benchmark routing performance with different number of routes
-func BenchmarkGinRouting10Routes(b *testing.B) {
-	benchmarkGinRouting(b, 10)
+func (c ginContext) RegexParam(index int) (string, error) {
+	return regexParam(c, c.patterns, index)
 }
 
-func BenchmarkGinRouting100Routes(b *testing.B) {
-	benchmarkGinRouting(b, 100)
+func (c ginContext) Query(name string) string {
+	return c.c.Query(name)
 }
 
-func BenchmarkGinRouting1000Routes(b *testing.B) {
-	benchmarkGinRouting(b, 1000)
+func (c ginContext) BindJSON(v any) error {
+	return c.c.ShouldBindJSON(v)
 }
 
-func benchmarkGinRouting(b *testing.B, numRoutes int) {
-	gin.SetMode(gin.ReleaseMode)
-	app := gin.New()
-
-	// Create multiple routes
-	for i := 0; i < numRoutes; i++ {
-		route := "/route" + strconv.Itoa(i)
-		app.GET(route, func(c *gin.Context) {
-			c.String(http.StatusOK, "Route "+strconv.Itoa(i))
-		})
-	}
-
-	// Test the last route (worst case scenario)
-	req := httptest.NewRequest("GET", "/route"+strconv.Itoa(numRoutes-1), nil)
-
-	b.ResetTimer()
-	b.ReportAllocs()
-
-	for i := 0; i < b.N; i++ {
-		w := httptest.NewRecorder()
-		app.ServeHTTP(w, req)
-	}
+func (c ginContext) BindForm(v any) error {
+	return c.c.ShouldBind(v)
 }
 
-// Gin Benchmark concurrent requests
-func BenchmarkGinConcurrentRequests(b *testing.B) {
-	app := setupGinApp()
-	req := httptest.NewRequest("GET", "/", nil)
-
-	b.ResetTimer()
-	b.ReportAllocs()
-
-	b.RunParallel(func(pb *testing.PB) {
-		for pb.Next() {
-			w := httptest.NewRecorder()
-			app.ServeHTTP(w, req)
-		}
-	})
+func (c ginContext) BindQuery(v any) error {
+	return c.c.ShouldBindQuery(v)
 }
 
-// Gin Benchmark memory allocations for different payload sizes
-func BenchmarkGinSmallPayload(b *testing.B) {
-	benchmarkGinPayloadSize(b, 100)
+func (c ginContext) FormFile(name string) (*multipart.FileHeader, error) {
+	return c.c.FormFile(name)
 }
 
-func BenchmarkGinMediumPayload(b *testing.B) {
-	benchmarkGinPayloadSize(b, 1024)
+func (c ginContext) JSON(code int, body H) error {
+	c.c.JSON(code, body)
+	return nil
 }
 
-func BenchmarkGinLargePayload(b *testing.B) {
-	benchmarkGinPayloadSize(b, 10240)
+func (c ginContext) String(code int, s string) error {
+	c.c.String(code, s)
+	return nil
 }
 
-func benchmarkGinPayloadSize(b *testing.B, size int) {
-	gin.SetMode(gin.ReleaseMode)
-	app := gin.New()
-
-	app.POST("/data", func(c *gin.Context) {
-		// Read and echo back the data
-		var data map[string]interface{}
-		if err := c.ShouldBindJSON(&data); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON"})
-			return
-		}
-		c.JSON(http.StatusOK, data)
-	})
+func (c ginContext) Method() string {
+	return c.c.Request.Method
+}
 
-	// Create payload of specified size
-	payload := make(map[string]string)
-	for i := 0; i < size/10; i++ { // Approximate size control
-		payload["key"+strconv.Itoa(i)] = "value" + strconv.Itoa(i)
-	}
+func (c ginContext) Path() string {
+	return c.c.Request.URL.Path
+}
 
-	jsonData, _ := json.Marshal(payload)
+func (c ginContext) Header(name string) string {
+	return c.c.GetHeader(name)
+}
 
-	b.ResetTimer()
-	b.ReportAllocs()
+func (c ginContext) SetHeader(name, value string) {
+	c.c.Header(name, value)
+}
 
-	for i := 0; i < b.N; i++ {
-		req := httptest.NewRequest("POST", "/data", bytes.NewBuffer(jsonData))
-		req.Header.Set("Content-Type", "application/json")
-		w := httptest.NewRecorder()
-		app.ServeHTTP(w, req)
-	}
+func (c ginContext) Stream(code int, contentType string, write StreamFunc) error {
+	c.c.Header("Content-Type", contentType)
+	c.c.Status(code)
+	return write(c.c.Writer, http.NewResponseController(c.c.Writer).Flush)
 }
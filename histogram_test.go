@@ -0,0 +1,163 @@
+package main
+
+import (
+	"math"
+	"math/bits"
+	"testing"
+	"time"
+)
+
+// Histogram layout in the style of HdrHistogram: values below histSubBucketCount get one bucket each,
+// every power of two above that is split into histSubBucketHalf linear buckets. Recorded values
+// are therefore reported with at most 1/histSubBucketHalf (~1.6%) relative error.
+const (
+	histSubBucketBits  = 7
+	histSubBucketCount = 1 << histSubBucketBits
+	histSubBucketHalf  = histSubBucketCount / 2
+)
+
+// histogram records latencies with bounded relative error and constant memory per power of two.
+// It is not safe for concurrent use; record per goroutine and merge.
+type histogram struct {
+	counts []uint64
+	total  uint64
+	min    int64
+	max    int64
+}
+
+func newHistogram() *histogram {
+	return &histogram{min: math.MaxInt64}
+}
+
+// histBucket returns the bucket index holding v
+func histBucket(v int64) int {
+	if v < histSubBucketCount {
+		return int(v)
+	}
+	shift := bits.Len64(uint64(v)) - histSubBucketBits
+	return shift*histSubBucketHalf + int(v>>shift)
+}
+
+// histBucketMax returns the highest value stored in bucket i
+func histBucketMax(i int) int64 {
+	if i < histSubBucketCount {
+		return int64(i)
+	}
+	shift := i/histSubBucketHalf - 1
+	lower := int64(i-shift*histSubBucketHalf) << shift
+	return lower + (1 << shift) - 1
+}
+
+// Record adds one observation of d
+func (h *histogram) Record(d time.Duration) {
+	v := int64(d)
+	if v < 0 {
+		v = 0
+	}
+
+	i := histBucket(v)
+	if i >= len(h.counts) {
+		counts := make([]uint64, i+1)
+		copy(counts, h.counts)
+		h.counts = counts
+	}
+
+	h.counts[i]++
+	h.total++
+	h.min = min(h.min, v)
+	h.max = max(h.max, v)
+}
+
+// Merge adds every observation of other to h
+func (h *histogram) Merge(other *histogram) {
+	if len(other.counts) > len(h.counts) {
+		counts := make([]uint64, len(other.counts))
+		copy(counts, h.counts)
+		h.counts = counts
+	}
+	for i, c := range other.counts {
+		h.counts[i] += c
+	}
+	h.total += other.total
+	h.min = min(h.min, other.min)
+	h.max = max(h.max, other.max)
+}
+
+// Count returns the number of recorded observations
+func (h *histogram) Count() uint64 {
+	return h.total
+}
+
+// Percentile returns the value at or below which q percent of observations fall, e.g. Percentile(99.9)
+func (h *histogram) Percentile(q float64) time.Duration {
+	if h.total == 0 {
+		return 0
+	}
+
+	rank := uint64(math.Ceil(q / 100 * float64(h.total)))
+	rank = max(rank, 1)
+
+	var seen uint64
+	for i, c := range h.counts {
+		seen += c
+		if seen >= rank {
+			// Never report beyond the largest value actually recorded
+			return time.Duration(min(histBucketMax(i), h.max))
+		}
+	}
+	return time.Duration(h.max)
+}
+
+// Max returns the largest recorded observation
+func (h *histogram) Max() time.Duration {
+	if h.total == 0 {
+		return 0
+	}
+	return time.Duration(h.max)
+}
+
+func TestHistogramBuckets(t *testing.T) {
+	prevMax := int64(-1)
+	for i := 0; i < 40*histSubBucketHalf; i++ {
+		hi := histBucketMax(i)
+		lo := prevMax + 1
+		if histBucket(lo) != i || histBucket(hi) != i {
+			t.Fatalf("bucket %d covers [%d, %d] but histBucket maps them to %d and %d", i, lo, hi, histBucket(lo), histBucket(hi))
+		}
+		prevMax = hi
+	}
+}
+
+func TestHistogramPercentiles(t *testing.T) {
+	h := newHistogram()
+	for v := 1; v <= 100000; v++ {
+		h.Record(time.Duration(v) * time.Microsecond)
+	}
+
+	for _, tc := range []struct {
+		q    float64
+		want time.Duration
+	}{
+		{50, 50 * time.Millisecond},
+		{90, 90 * time.Millisecond},
+		{99, 99 * time.Millisecond},
+		{99.9, 99900 * time.Microsecond},
+		{100, 100 * time.Millisecond},
+	} {
+		got := h.Percentile(tc.q)
+		if diff := math.Abs(float64(got-tc.want)) / float64(tc.want); diff > 1.0/histSubBucketHalf {
+			t.Errorf("p%v = %v, want %v within %.1f%%", tc.q, got, tc.want, 100.0/histSubBucketHalf)
+		}
+	}
+}
+
+func TestHistogramMerge(t *testing.T) {
+	a, b := newHistogram(), newHistogram()
+	a.Record(time.Millisecond)
+	b.Record(time.Second)
+	a.Merge(b)
+
+	if a.Count() != 2 || a.Max() != time.Second || a.Percentile(50) > time.Millisecond+time.Millisecond/histSubBucketHalf {
+		t.Errorf("merged histogram: count %d, max %v, p50 %v", a.Count(), a.Max(), a.Percentile(50))
+	}
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+// http2Mode selects how HTTP/2 is negotiated between the load client and the server
+type http2Mode int
+
+const (
+	// http2Cleartext speaks HTTP/2 with prior knowledge over plain TCP (h2c)
+	http2Cleartext http2Mode = iota
+	// http2TLS negotiates h2 through ALPN over TLS with an in-memory self-signed certificate
+	http2TLS
+)
+
+// testCertificate is a self-signed loopback certificate, generated once per test binary
+var testCertificate = sync.OnceValues(func() (tls.Certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{Organization: []string{"tree-framework-benchmark"}},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1)},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}, nil
+})
+
+// startHTTP2Server serves f over HTTP/2 only on a loopback port and returns a client multiplexing
+// every request over a single connection, the server's base URL and a function that stops the server.
+// Fiber served natively by fasthttp has no HTTP/2 and is skipped; in adaptor mode it is an http.Handler
+// like the others.
+func startHTTP2Server(tb testing.TB, f Framework, mode http2Mode) (*http.Client, string, func()) {
+	tb.Helper()
+
+	if fiber, ok := f.(*fiberFramework); ok && fiber.mode != fiberModeAdaptor {
+		tb.Skip("Fiber is not served by net/http in this mode and has no HTTP/2 support")
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	var protocols http.Protocols
+	srv := &http.Server{Handler: f, Protocols: &protocols}
+	// One connection per host: requests beyond the server's stream limit wait instead of dialing another one
+	transport := &http.Transport{Protocols: &protocols, MaxConnsPerHost: 1}
+
+	scheme := "http://"
+	switch mode {
+	case http2Cleartext:
+		protocols.SetUnencryptedHTTP2(true)
+		go srv.Serve(ln)
+
+	case http2TLS:
+		cert, err := testCertificate()
+		if err != nil {
+			tb.Fatal(err)
+		}
+		roots := x509.NewCertPool()
+		roots.AddCert(cert.Leaf)
+
+		protocols.SetHTTP2(true)
+		srv.TLSConfig = &tls.Config{Certificates: []tls.Certificate{cert}}
+		transport.TLSClientConfig = &tls.Config{RootCAs: roots}
+		go srv.ServeTLS(ln, "", "")
+		scheme = "https://"
+	}
+
+	return &http.Client{Transport: transport}, scheme + ln.Addr().String(), func() {
+		transport.CloseIdleConnections()
+		if err := srv.Shutdown(context.Background()); err != nil {
+			tb.Errorf("stopping server: %v", err)
+		}
+	}
+}
+
+// http2Target returns a loadTarget serving frameworks over HTTP/2 in the given mode
+func http2Target(mode http2Mode) loadTarget {
+	return func(tb testing.TB, f Framework, sc scenario) (*loadClient, func()) {
+		client, baseURL, stop := startHTTP2Server(tb, f, mode)
+		return &loadClient{client: client, url: baseURL + sc.target, sc: sc}, stop
+	}
+}
+
+// TestHTTP2 verifies every net/http framework answers every scenario over h2c and TLS
+// with an HTTP/2 response carrying the golden status and body
+func TestHTTP2(t *testing.T) {
+	defer silenceStdout(t)()
+
+	modes := []struct {
+		name string
+		mode http2Mode
+	}{
+		{"H2C", http2Cleartext},
+		{"TLS", http2TLS},
+	}
+
+	for _, m := range modes {
+		for _, fw := range frameworks {
+			if fw.inProcessOnly {
+				continue
+			}
+			t.Run(m.name+"/"+fw.name, func(t *testing.T) {
+				for _, sc := range scenarios {
+					sc := sc.forFramework(fw.name)
+					if sc.want.panics {
+						continue
+					}
+					f := fw.new()
+					sc.routes(f)
+					c, stop := http2Target(m.mode)(t, f, sc)
+
+					resp, err := c.send()
+					if err != nil {
+						stop()
+						t.Fatalf("%s: %v", sc.name, err)
+					}
+					body, _ := io.ReadAll(resp.Body)
+					resp.Body.Close()
+					stop()
+
+					if resp.ProtoMajor != 2 {
+						t.Errorf("%s: served over %s, want HTTP/2", sc.name, resp.Proto)
+					}
+					if resp.StatusCode != sc.want.status {
+						t.Errorf("%s: status %d, want %d", sc.name, resp.StatusCode, sc.want.status)
+					}
+					if want, got := sc.want.body, string(body); !sc.want.anyBody && normalizeJSON(want) != normalizeJSON(got) {
+						t.Errorf("%s: body (-want +got):\n%s", sc.name, lineDiff(want, got))
+					}
+				}
+			})
+		}
+	}
+}
+
+// BenchmarkLoadH2C measures throughput and latency with -load.streams concurrent streams
+// multiplexed over one cleartext HTTP/2 connection
+func BenchmarkLoadH2C(b *testing.B) {
+	runLoad(b, http2Target(http2Cleartext), func(c *loadClient, n int) loadResult {
+		return runClosedLoop(c, n, *loadStreams)
+	})
+}
+
+// BenchmarkLoadH2TLS measures throughput and latency with -load.streams concurrent streams
+// multiplexed over one HTTP/2 connection over TLS
+func BenchmarkLoadH2TLS(b *testing.B) {
+	runLoad(b, http2Target(http2TLS), func(c *loadClient, n int) loadResult {
+		return runClosedLoop(c, n, *loadStreams)
+	})
+}
@@ -0,0 +1,76 @@
+package benchresult
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Baseline is a named, saved set of benchmark samples that later runs are compared against
+type Baseline struct {
+	Name string `json:"name"`
+	// TreeVersion is the tree-framework version required by go.mod when the baseline was saved
+	TreeVersion string    `json:"tree_version,omitempty"`
+	Created     time.Time `json:"created"`
+	Results     []Result  `json:"results"`
+}
+
+var validBaselineName = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+
+// BaselinePath returns the file a baseline called name is stored in under dir
+func BaselinePath(dir, name string) (string, error) {
+	if !validBaselineName.MatchString(name) {
+		return "", fmt.Errorf("invalid baseline name %q: use letters, digits, '.', '_' or '-'", name)
+	}
+	return filepath.Join(dir, name+".json"), nil
+}
+
+// SaveBaseline writes b to path, creating its directory if needed
+func SaveBaseline(path string, b *Baseline) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// LoadBaseline reads a baseline written by SaveBaseline
+func LoadBaseline(path string) (*Baseline, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var b Baseline
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &b, nil
+}
+
+// ModuleVersion returns the version of module required by the go.mod file at path, or "" if it is not required
+func ModuleVersion(path, module string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(strings.TrimPrefix(strings.TrimSpace(scanner.Text()), "require "))
+		if len(fields) >= 2 && fields[0] == module {
+			return fields[1], nil
+		}
+	}
+	return "", scanner.Err()
+}
@@ -0,0 +1,168 @@
+// Package benchresult parses Go benchmark results and arranges them into
+// framework × scenario matrices for reporting.
+package benchresult
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// Standard metric units reported by `go test -bench -benchmem`
+const (
+	NsPerOp     = "ns/op"
+	BytesPerOp  = "B/op"
+	AllocsPerOp = "allocs/op"
+)
+
+// Result is one benchmark result line
+type Result struct {
+	// Benchmark is the top-level benchmark without the "Benchmark" prefix, e.g. "Frameworks"
+	Benchmark string `json:"benchmark"`
+	// Framework is the first sub-benchmark name, e.g. "Tree"
+	Framework string `json:"framework"`
+	// Scenario is the rest of the sub-benchmark name, e.g. "SimpleGET"
+	Scenario   string             `json:"scenario"`
+	Procs      int                `json:"procs"`
+	Iterations int                `json:"iterations"`
+	Metrics    map[string]float64 `json:"metrics"` // keyed by unit, e.g. "ns/op"
+}
+
+// Key identifies the benchmark a result belongs to, ignoring GOMAXPROCS
+func (r Result) Key() string {
+	return r.Benchmark + "/" + r.Framework + "/" + r.Scenario
+}
+
+// Parse reads the text output of `go test -bench` and returns every benchmark result line in order.
+// Other lines (goos, PASS, logs) are ignored. Repeated lines from -count are returned as separate results.
+func Parse(r io.Reader) ([]Result, error) {
+	var results []Result
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "Benchmark") {
+			continue
+		}
+
+		result, ok, err := parseLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNum, err)
+		}
+		if ok {
+			results = append(results, result)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading benchmark output: %w", err)
+	}
+
+	return results, nil
+}
+
+// ParseFiles parses every file in paths in order, or stdin when paths is empty, and fails when
+// they hold no benchmark results at all
+func ParseFiles(paths []string, stdin io.Reader) ([]Result, error) {
+	var results []Result
+	if len(paths) == 0 {
+		var err error
+		if results, err = Parse(stdin); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, path := range paths {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		fileResults, err := Parse(file)
+		file.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		results = append(results, fileResults...)
+	}
+
+	if len(results) == 0 {
+		return nil, errors.New("no benchmark results found in input")
+	}
+	return results, nil
+}
+
+// parseLine parses "BenchmarkName-8  1000  1234 ns/op  56 B/op  7 allocs/op".
+// Lines that start with "Benchmark" but carry no measurements, such as the name printed
+// before a failing benchmark's log output, are skipped.
+func parseLine(line string) (Result, bool, error) {
+	fields := strings.Fields(line)
+	if len(fields) < 4 || len(fields)%2 != 0 {
+		return Result{}, false, nil
+	}
+
+	iterations, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return Result{}, false, nil
+	}
+
+	name, procs := splitProcs(fields[0])
+	result := NewResult(name, procs)
+	result.Iterations = iterations
+
+	for i := 2; i < len(fields); i += 2 {
+		value, err := strconv.ParseFloat(fields[i], 64)
+		if err != nil {
+			return Result{}, false, fmt.Errorf("invalid value %q for %s: %w", fields[i], fields[i+1], err)
+		}
+		result.Metrics[fields[i+1]] = value
+	}
+
+	return result, true, nil
+}
+
+// splitProcs splits the "-8" GOMAXPROCS suffix off a benchmark name
+func splitProcs(name string) (string, int) {
+	i := strings.LastIndexByte(name, '-')
+	if i == -1 {
+		return name, 1
+	}
+	procs, err := strconv.Atoi(name[i+1:])
+	if err != nil {
+		return name, 1
+	}
+	return name[:i], procs
+}
+
+// NewResult creates an empty result for a full benchmark name such as "BenchmarkFrameworks/Tree/SimpleGET"
+func NewResult(name string, procs int) Result {
+	parts := strings.SplitN(strings.TrimPrefix(name, "Benchmark"), "/", 3)
+
+	result := Result{Benchmark: parts[0], Procs: procs, Metrics: make(map[string]float64)}
+	if len(parts) > 1 {
+		result.Framework = parts[1]
+	}
+	if len(parts) > 2 {
+		result.Scenario = parts[2]
+	}
+	return result
+}
+
+// FromBenchmarkResult converts a result obtained from testing.Benchmark
+func FromBenchmarkResult(name string, procs int, br testing.BenchmarkResult) Result {
+	result := NewResult(name, procs)
+	result.Iterations = br.N
+	result.Metrics[NsPerOp] = float64(br.NsPerOp())
+	if br.MemAllocs > 0 || br.MemBytes > 0 {
+		result.Metrics[BytesPerOp] = float64(br.AllocedBytesPerOp())
+		result.Metrics[AllocsPerOp] = float64(br.AllocsPerOp())
+	}
+	for unit, value := range br.Extra {
+		result.Metrics[unit] = value
+	}
+	return result
+}
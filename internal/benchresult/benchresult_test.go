@@ -0,0 +1,151 @@
+package benchresult
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const sampleOutput = `goos: linux
+goarch: amd64
+pkg: tree-framework-benchmark
+BenchmarkFrameworks/Tree/SimpleGET-8         	  200000	      1700 ns/op	    1120 B/op	      13 allocs/op
+BenchmarkFrameworks/Tree/SimpleGET-8         	  200000	      1900 ns/op	    1120 B/op	      13 allocs/op
+BenchmarkFrameworks/Gin/SimpleGET-8          	  300000	      1400 ns/op	    1040 B/op	       9 allocs/op
+BenchmarkFrameworks/Gin/Routing10Routes-8    	  300000	      1023 ns/op	    1040 B/op	       9 allocs/op
+BenchmarkLoadClosedLoop/Tree/SimpleGET-8     	   28034	     36844 ns/op	    909311 p50-ns	     27181 req/s
+BenchmarkFrameworks/Tree/Failing
+--- FAIL: BenchmarkFrameworks/Tree/Failing
+PASS
+ok  	tree-framework-benchmark	0.214s
+`
+
+func TestParse(t *testing.T) {
+	results, err := Parse(strings.NewReader(sampleOutput))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 5 {
+		t.Fatalf("got %d results, want 5", len(results))
+	}
+
+	r := results[0]
+	if r.Benchmark != "Frameworks" || r.Framework != "Tree" || r.Scenario != "SimpleGET" || r.Procs != 8 || r.Iterations != 200000 {
+		t.Errorf("unexpected result %+v", r)
+	}
+	if r.Metrics[NsPerOp] != 1700 || r.Metrics[BytesPerOp] != 1120 || r.Metrics[AllocsPerOp] != 13 {
+		t.Errorf("unexpected metrics %v", r.Metrics)
+	}
+
+	load := results[4]
+	if load.Benchmark != "LoadClosedLoop" || load.Metrics["req/s"] != 27181 || load.Metrics["p50-ns"] != 909311 {
+		t.Errorf("unexpected custom metrics %+v", load)
+	}
+}
+
+func TestParseFiles(t *testing.T) {
+	dir := t.TempDir()
+	first, second := filepath.Join(dir, "first.txt"), filepath.Join(dir, "second.txt")
+	lines := strings.Split(sampleOutput, "\n")
+	if err := os.WriteFile(first, []byte(strings.Join(lines[:5], "\n")), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(second, []byte(strings.Join(lines[5:], "\n")), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := ParseFiles([]string{first, second}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 5 || results[0].Metrics[NsPerOp] != 1700 || results[4].Benchmark != "LoadClosedLoop" {
+		t.Errorf("unexpected results %+v", results)
+	}
+
+	if results, err := ParseFiles(nil, strings.NewReader(sampleOutput)); err != nil || len(results) != 5 {
+		t.Errorf("stdin: got %d results, err %v; want 5", len(results), err)
+	}
+	if _, err := ParseFiles(nil, strings.NewReader("PASS\n")); err == nil {
+		t.Error("no error for input without results")
+	}
+	if _, err := ParseFiles([]string{filepath.Join(dir, "missing.txt")}, nil); err == nil {
+		t.Error("no error for a missing file")
+	}
+}
+
+func TestNewMatrices(t *testing.T) {
+	results, err := Parse(strings.NewReader(sampleOutput))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	matrices := NewMatrices(results)
+	if len(matrices) != 2 {
+		t.Fatalf("got %d matrices, want 2", len(matrices))
+	}
+
+	m := matrices[0]
+	if strings.Join(m.Frameworks, ",") != "Tree,Gin" || strings.Join(m.Scenarios, ",") != "SimpleGET,Routing10Routes" {
+		t.Errorf("unexpected layout: frameworks %v, scenarios %v", m.Frameworks, m.Scenarios)
+	}
+
+	tree := m.Cells["SimpleGET"]["Tree"]
+	if tree.NsPerOp != 1800 || tree.Samples != 2 {
+		t.Errorf("Tree/SimpleGET = %+v, want median 1800 ns/op over 2 samples", tree)
+	}
+	if got := m.Fastest("SimpleGET"); got != "Gin" {
+		t.Errorf("Fastest(SimpleGET) = %q, want Gin", got)
+	}
+	if got := m.Fastest("Routing10Routes"); got != "Gin" {
+		t.Errorf("Fastest(Routing10Routes) = %q, want Gin", got)
+	}
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	results, _ := Parse(strings.NewReader(sampleOutput))
+
+	var buf bytes.Buffer
+	if err := WriteMarkdown(&buf, NewMatrices(results)[:1]); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `### Frameworks
+
+| Scenario | Tree | Gin | Fastest |
+|---|---:|---:|---|
+| SimpleGET | 1800 ns/op<br>1120 B/op<br>13 allocs/op | **1400 ns/op<br>1040 B/op<br>9 allocs/op** | Gin |
+| Routing10Routes | - | **1023 ns/op<br>1040 B/op<br>9 allocs/op** | Gin |
+`
+	if buf.String() != want {
+		t.Errorf("markdown:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	results, _ := Parse(strings.NewReader(sampleOutput))
+
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, NewMatrices(results)[:1]); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `benchmark,scenario,framework,ns/op,B/op,allocs/op,samples,fastest
+Frameworks,SimpleGET,Tree,1800,1120,13,2,false
+Frameworks,SimpleGET,Gin,1400,1040,9,1,true
+Frameworks,Routing10Routes,Gin,1023,1040,9,1,true
+`
+	if buf.String() != want {
+		t.Errorf("csv:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
+
+func TestFromBenchmarkResult(t *testing.T) {
+	br := testing.BenchmarkResult{N: 100, T: 1000000, MemAllocs: 300, MemBytes: 6400, Extra: map[string]float64{"req/s": 5}}
+
+	r := FromBenchmarkResult("BenchmarkFrameworks/Tree/SimpleGET", 4, br)
+	if r.Key() != "Frameworks/Tree/SimpleGET" || r.Metrics[NsPerOp] != 10000 || r.Metrics[BytesPerOp] != 64 || r.Metrics[AllocsPerOp] != 3 || r.Metrics["req/s"] != 5 {
+		t.Errorf("unexpected result %+v", r)
+	}
+}
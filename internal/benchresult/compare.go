@@ -0,0 +1,209 @@
+package benchresult
+
+import (
+	"math"
+	"sort"
+)
+
+// Comparison is the change of one metric between a baseline and a new run
+type Comparison struct {
+	Key        string  `json:"key"`
+	Unit       string  `json:"unit"`
+	Old        float64 `json:"old"`   // baseline median
+	New        float64 `json:"new"`   // new run median
+	Delta      float64 `json:"delta"` // percent change of the median, positive is worse for every standard unit
+	P          float64 `json:"p"`     // two-sided Mann-Whitney U p-value
+	OldSamples int     `json:"old_samples"`
+	NewSamples int     `json:"new_samples"`
+
+	deterministic bool
+}
+
+// Significant reports whether the change is unlikely to be noise at level alpha.
+// Allocation metrics that did not vary at all within either run are always significant when
+// their values differ, even with a single sample; timings always need the test.
+func (c Comparison) Significant(alpha float64) bool {
+	if c.Old == c.New {
+		return false
+	}
+	return c.deterministic || c.P < alpha
+}
+
+// Compare pairs old and new samples by benchmark key and compares the medians of every unit.
+// Benchmarks or units missing from either side are skipped; Missing lists the benchmarks of old
+// that new lacks.
+func Compare(old, new []Result, units ...string) []Comparison {
+	oldByKey := groupByKey(old)
+	newByKey := groupByKey(new)
+
+	var keys []string
+	for key := range newByKey {
+		if _, ok := oldByKey[key]; ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	var comparisons []Comparison
+	for _, key := range keys {
+		for _, unit := range units {
+			xs, ys := Values(oldByKey[key], unit), Values(newByKey[key], unit)
+			if len(xs) == 0 || len(ys) == 0 {
+				continue
+			}
+
+			c := Comparison{
+				Key:           key,
+				Unit:          unit,
+				Old:           Median(xs),
+				New:           Median(ys),
+				P:             MannWhitneyU(xs, ys),
+				OldSamples:    len(xs),
+				NewSamples:    len(ys),
+				deterministic: exactUnit(unit) && constant(xs) && constant(ys),
+			}
+			switch {
+			case c.Old != 0:
+				c.Delta = (c.New - c.Old) / c.Old * 100
+			case c.New != 0:
+				c.Delta = math.Inf(1)
+			}
+			comparisons = append(comparisons, c)
+		}
+	}
+
+	return comparisons
+}
+
+// Missing returns the keys of benchmarks in old with no result in new, sorted. A benchmark that
+// failed or was removed since the baseline has no result, so Compare alone cannot report it.
+func Missing(old, new []Result) []string {
+	newByKey := groupByKey(new)
+
+	var keys []string
+	for key := range groupByKey(old) {
+		if _, ok := newByKey[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func groupByKey(results []Result) map[string][]Result {
+	grouped := make(map[string][]Result)
+	for _, r := range results {
+		grouped[r.Key()] = append(grouped[r.Key()], r)
+	}
+	return grouped
+}
+
+// exactUnit reports whether unit counts allocations, which repeat exactly from run to run
+// unless the code changed
+func exactUnit(unit string) bool {
+	return unit == AllocsPerOp || unit == BytesPerOp
+}
+
+func constant(values []float64) bool {
+	for _, v := range values[1:] {
+		if v != values[0] {
+			return false
+		}
+	}
+	return true
+}
+
+// MannWhitneyU returns the two-sided p-value of the Mann-Whitney U test that xs and ys come
+// from the same distribution. Small samples without ties use the exact distribution of U,
+// everything else the normal approximation with tie and continuity correction.
+func MannWhitneyU(xs, ys []float64) float64 {
+	n1, n2 := len(xs), len(ys)
+	if n1 == 0 || n2 == 0 {
+		return 1
+	}
+
+	type sample struct {
+		value float64
+		first bool
+	}
+	all := make([]sample, 0, n1+n2)
+	for _, x := range xs {
+		all = append(all, sample{x, true})
+	}
+	for _, y := range ys {
+		all = append(all, sample{y, false})
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].value < all[j].value })
+
+	// Rank with ties sharing the average of their positions
+	var rankSum, tieSum float64
+	for i := 0; i < len(all); {
+		j := i
+		for j < len(all) && all[j].value == all[i].value {
+			j++
+		}
+		rank := float64(i+j+1) / 2
+		for k := i; k < j; k++ {
+			if all[k].first {
+				rankSum += rank
+			}
+		}
+		t := float64(j - i)
+		tieSum += t*t*t - t
+		i = j
+	}
+
+	u1 := rankSum - float64(n1*(n1+1))/2
+	u := math.Min(u1, float64(n1*n2)-u1)
+
+	if tieSum == 0 && n1 <= 50 && n2 <= 50 {
+		return math.Min(1, 2*exactUCDF(n1, n2, int(u)))
+	}
+
+	n := float64(n1 + n2)
+	mean := float64(n1*n2) / 2
+	variance := float64(n1*n2) / 12 * ((n + 1) - tieSum/(n*(n-1)))
+	if variance <= 0 {
+		return 1
+	}
+	z := (math.Abs(u-mean) - 0.5) / math.Sqrt(variance)
+	if z < 0 {
+		return 1
+	}
+	return math.Min(1, math.Erfc(z/math.Sqrt2))
+}
+
+// exactUCDF returns P(U <= u) for samples of size n1 and n2 without ties
+func exactUCDF(n1, n2, u int) float64 {
+	// counts[a][b][k] is the number of orderings of a first and b second samples with U = k,
+	// using counts(a, b, k) = counts(a-1, b, k-b) + counts(a, b-1, k)
+	counts := make([][][]float64, n1+1)
+	for a := 0; a <= n1; a++ {
+		counts[a] = make([][]float64, n2+1)
+		for b := 0; b <= n2; b++ {
+			c := make([]float64, a*b+1)
+			if a == 0 || b == 0 {
+				c[0] = 1
+			} else {
+				for k := range c {
+					if k >= b && k-b < len(counts[a-1][b]) {
+						c[k] += counts[a-1][b][k-b]
+					}
+					if k < len(counts[a][b-1]) {
+						c[k] += counts[a][b-1][k]
+					}
+				}
+			}
+			counts[a][b] = c
+		}
+	}
+
+	var below, total float64
+	for k, c := range counts[n1][n2] {
+		total += c
+		if k <= u {
+			below += c
+		}
+	}
+	return below / total
+}
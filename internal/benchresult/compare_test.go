@@ -0,0 +1,134 @@
+package benchresult
+
+import (
+	"math"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestMannWhitneyU(t *testing.T) {
+	tests := []struct {
+		name   string
+		xs, ys []float64
+		want   float64
+	}{
+		// Exact: the only more extreme ordering of 5+5 samples is the mirrored one, 2/252
+		{"separated exact", []float64{1, 2, 3, 4, 5}, []float64{6, 7, 8, 9, 10}, 2.0 / 252},
+		{"interleaved exact", []float64{1, 3, 5}, []float64{2, 4, 6}, 0.7},
+		{"identical", []float64{7, 7, 7}, []float64{7, 7, 7}, 1},
+		// Normal approximation with ties: U=0, mean 12.5, tie-corrected sd 3.819
+		{"separated ties", []float64{1, 1, 2, 2, 3}, []float64{4, 4, 5, 5, 6}, 0.0109},
+		{"empty", nil, []float64{1}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MannWhitneyU(tt.xs, tt.ys); math.Abs(got-tt.want) > 0.0005 {
+				t.Errorf("MannWhitneyU = %.4f, want %.4f", got, tt.want)
+			}
+		})
+	}
+}
+
+func samples(name, unit string, values ...float64) []Result {
+	var results []Result
+	for _, v := range values {
+		r := NewResult(name, 8)
+		r.Metrics[unit] = v
+		results = append(results, r)
+	}
+	return results
+}
+
+func TestCompare(t *testing.T) {
+	old := append(samples("BenchmarkFrameworks/Tree/SimpleGET", NsPerOp, 100, 101, 102, 103, 104),
+		samples("BenchmarkFrameworks/Tree/Only", NsPerOp, 1)...)
+	old = append(old, samples("BenchmarkFrameworks/Tree/SimpleGET", AllocsPerOp, 13)...)
+	new := append(samples("BenchmarkFrameworks/Tree/SimpleGET", NsPerOp, 120, 121, 122, 123, 124),
+		samples("BenchmarkFrameworks/Tree/SimpleGET", AllocsPerOp, 14)...)
+
+	comparisons := Compare(old, new, NsPerOp, AllocsPerOp)
+	if len(comparisons) != 2 {
+		t.Fatalf("got %d comparisons, want 2: %+v", len(comparisons), comparisons)
+	}
+
+	ns := comparisons[0]
+	if ns.Unit != NsPerOp || ns.Old != 102 || ns.New != 122 || !ns.Significant(0.05) {
+		t.Errorf("unexpected ns/op comparison %+v", ns)
+	}
+	if math.Abs(ns.Delta-19.61) > 0.01 {
+		t.Errorf("delta = %.2f%%, want 19.61%%", ns.Delta)
+	}
+
+	// A single sample is never significant by the test, but a constant allocation count that changed is
+	allocs := comparisons[1]
+	if allocs.Unit != AllocsPerOp || allocs.P != 1 || !allocs.Significant(0.05) {
+		t.Errorf("unexpected allocs/op comparison %+v", allocs)
+	}
+
+	// A single timing sample per side is noise, however large the change
+	single := Compare(samples("BenchmarkFrameworks/Tree/SimpleGET", NsPerOp, 1000),
+		samples("BenchmarkFrameworks/Tree/SimpleGET", NsPerOp, 1060), NsPerOp)
+	if len(single) != 1 || single[0].P != 1 || single[0].Significant(0.05) {
+		t.Errorf("single-sample ns/op change reported as significant: %+v", single)
+	}
+}
+
+func TestMissing(t *testing.T) {
+	old := append(samples("BenchmarkFrameworks/Tree/SimpleGET", NsPerOp, 100, 101),
+		samples("BenchmarkFrameworks/Tree/PostWithJSON", NsPerOp, 200)...)
+	old = append(old, samples("BenchmarkFrameworks/Gin/SimpleGET", NsPerOp, 90)...)
+	new := append(samples("BenchmarkFrameworks/Tree/SimpleGET", NsPerOp, 100),
+		samples("BenchmarkFrameworks/Tree/NotFound", NsPerOp, 50)...)
+
+	got := Missing(old, new)
+	want := []string{"Frameworks/Gin/SimpleGET", "Frameworks/Tree/PostWithJSON"}
+	if !slices.Equal(got, want) {
+		t.Errorf("Missing = %q, want %q", got, want)
+	}
+}
+
+func TestCompareNoise(t *testing.T) {
+	old := samples("BenchmarkFrameworks/Tree/SimpleGET", NsPerOp, 100, 130, 90, 110, 120)
+	new := samples("BenchmarkFrameworks/Tree/SimpleGET", NsPerOp, 105, 95, 125, 115, 135)
+
+	c := Compare(old, new, NsPerOp)[0]
+	if c.Significant(0.05) {
+		t.Errorf("overlapping samples reported as significant: %+v", c)
+	}
+}
+
+func TestBaselineRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := BaselinePath(dir, "../escape"); err == nil {
+		t.Error("BaselinePath accepted a name with a path separator")
+	}
+
+	path, err := BaselinePath(dir, "v1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &Baseline{Name: "v1.0.0", TreeVersion: "v1.0.0", Results: samples("BenchmarkFrameworks/Tree/SimpleGET", NsPerOp, 100, 101)}
+	if err := SaveBaseline(path, want); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := LoadBaseline(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Name != want.Name || len(got.Results) != 2 || got.Results[1].Metrics[NsPerOp] != 101 || got.Results[0].Key() != "Frameworks/Tree/SimpleGET" {
+		t.Errorf("round trip = %+v, want %+v", got, want)
+	}
+}
+
+func TestModuleVersion(t *testing.T) {
+	got, err := ModuleVersion(filepath.Join("..", "..", "go.mod"), "github.com/catalinfl/tree-framework")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got == "" {
+		t.Error("tree-framework not found in go.mod")
+	}
+}
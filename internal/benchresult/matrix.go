@@ -0,0 +1,211 @@
+package benchresult
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Cell is the summary of every sample of one framework/scenario pair
+type Cell struct {
+	NsPerOp     float64 `json:"ns_per_op"`
+	BytesPerOp  float64 `json:"bytes_per_op"`
+	AllocsPerOp float64 `json:"allocs_per_op"`
+	Samples     int     `json:"samples"`
+}
+
+// Matrix holds the results of one top-level benchmark, one row per scenario and one column per framework
+type Matrix struct {
+	Benchmark  string                     `json:"benchmark"`
+	Frameworks []string                   `json:"frameworks"`
+	Scenarios  []string                   `json:"scenarios"`
+	Cells      map[string]map[string]Cell `json:"cells"` // scenario -> framework -> cell
+}
+
+// Fastest returns the framework with the lowest ns/op for scenario, or "" if it has no results
+func (m *Matrix) Fastest(scenario string) string {
+	fastest := ""
+	for _, framework := range m.Frameworks {
+		cell, ok := m.Cells[scenario][framework]
+		if !ok {
+			continue
+		}
+		if fastest == "" || cell.NsPerOp < m.Cells[scenario][fastest].NsPerOp {
+			fastest = framework
+		}
+	}
+	return fastest
+}
+
+// NewMatrices groups results by top-level benchmark. Frameworks and scenarios keep the order they
+// first appear in; repeated samples (-count) are summarized by their median.
+func NewMatrices(results []Result) []*Matrix {
+	var matrices []*Matrix
+	byBenchmark := make(map[string]*Matrix)
+	samples := make(map[string][]Result)
+
+	for _, r := range results {
+		m, ok := byBenchmark[r.Benchmark]
+		if !ok {
+			m = &Matrix{Benchmark: r.Benchmark, Cells: make(map[string]map[string]Cell)}
+			byBenchmark[r.Benchmark] = m
+			matrices = append(matrices, m)
+		}
+
+		if !contains(m.Frameworks, r.Framework) {
+			m.Frameworks = append(m.Frameworks, r.Framework)
+		}
+		if !contains(m.Scenarios, r.Scenario) {
+			m.Scenarios = append(m.Scenarios, r.Scenario)
+		}
+		samples[r.Key()] = append(samples[r.Key()], r)
+	}
+
+	for _, rs := range samples {
+		r := rs[0]
+		m := byBenchmark[r.Benchmark]
+		if m.Cells[r.Scenario] == nil {
+			m.Cells[r.Scenario] = make(map[string]Cell)
+		}
+		m.Cells[r.Scenario][r.Framework] = Cell{
+			NsPerOp:     Median(Values(rs, NsPerOp)),
+			BytesPerOp:  Median(Values(rs, BytesPerOp)),
+			AllocsPerOp: Median(Values(rs, AllocsPerOp)),
+			Samples:     len(rs),
+		}
+	}
+
+	return matrices
+}
+
+// Values returns the unit metric of every result that reported it
+func Values(results []Result, unit string) []float64 {
+	values := make([]float64, 0, len(results))
+	for _, r := range results {
+		if v, ok := r.Metrics[unit]; ok {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// Median returns the median of values, or 0 if there are none
+func Median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteJSON writes the matrices as an indented JSON array
+func WriteJSON(w io.Writer, matrices []*Matrix) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(matrices)
+}
+
+// WriteCSV writes one row per benchmark, scenario and framework
+func WriteCSV(w io.Writer, matrices []*Matrix) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"benchmark", "scenario", "framework", NsPerOp, BytesPerOp, AllocsPerOp, "samples", "fastest"}); err != nil {
+		return err
+	}
+
+	for _, m := range matrices {
+		for _, scenario := range m.Scenarios {
+			fastest := m.Fastest(scenario)
+			for _, framework := range m.Frameworks {
+				cell, ok := m.Cells[scenario][framework]
+				if !ok {
+					continue
+				}
+				record := []string{
+					m.Benchmark,
+					scenario,
+					framework,
+					formatFloat(cell.NsPerOp),
+					formatFloat(cell.BytesPerOp),
+					formatFloat(cell.AllocsPerOp),
+					strconv.Itoa(cell.Samples),
+					strconv.FormatBool(framework == fastest),
+				}
+				if err := cw.Write(record); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteMarkdown writes one table per matrix with the fastest framework of every row in bold
+func WriteMarkdown(w io.Writer, matrices []*Matrix) error {
+	var sb strings.Builder
+
+	for i, m := range matrices {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		fmt.Fprintf(&sb, "### %s\n\n", m.Benchmark)
+
+		sb.WriteString("| Scenario |")
+		for _, framework := range m.Frameworks {
+			fmt.Fprintf(&sb, " %s |", framework)
+		}
+		sb.WriteString(" Fastest |\n|---|")
+		for range m.Frameworks {
+			sb.WriteString("---:|")
+		}
+		sb.WriteString("---|\n")
+
+		for _, scenario := range m.Scenarios {
+			fastest := m.Fastest(scenario)
+			fmt.Fprintf(&sb, "| %s |", scenario)
+			for _, framework := range m.Frameworks {
+				cell, ok := m.Cells[scenario][framework]
+				switch {
+				case !ok:
+					sb.WriteString(" - |")
+				case framework == fastest:
+					fmt.Fprintf(&sb, " **%s** |", formatCell(cell))
+				default:
+					fmt.Fprintf(&sb, " %s |", formatCell(cell))
+				}
+			}
+			fmt.Fprintf(&sb, " %s |\n", fastest)
+		}
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func formatCell(c Cell) string {
+	return fmt.Sprintf("%s ns/op<br>%s B/op<br>%s allocs/op", formatFloat(c.NsPerOp), formatFloat(c.BytesPerOp), formatFloat(c.AllocsPerOp))
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
@@ -0,0 +1,334 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+var (
+	loadEnabled = flag.Bool("load", false, "run the real-socket load benchmarks (BenchmarkLoadClosedLoop, BenchmarkLoadOpenLoop, BenchmarkLoadH2C, BenchmarkLoadH2TLS)")
+	loadClients = flag.Int("load.clients", 32, "number of concurrent keep-alive clients in load benchmarks")
+	loadRate    = flag.Int("load.rate", 5000, "requests per second issued by the open-loop load benchmark")
+	loadStreams = flag.Int("load.streams", 100, "number of concurrent streams multiplexed over one connection in HTTP/2 load benchmarks")
+)
+
+// socketServer is implemented by frameworks that serve sockets with their own server instead of net/http
+type socketServer interface {
+	Serve(ln net.Listener) error
+	Shutdown() error
+}
+
+// startServer serves f on a loopback port and returns its base URL and a function that stops it
+func startServer(tb testing.TB, f Framework) (string, func()) {
+	tb.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	var stop func() error
+	if s, ok := f.(socketServer); ok {
+		go s.Serve(ln)
+		stop = s.Shutdown
+	} else {
+		srv := &http.Server{Handler: f}
+		go srv.Serve(ln)
+		stop = func() error { return srv.Shutdown(context.Background()) }
+	}
+
+	return "http://" + ln.Addr().String(), func() {
+		if err := stop(); err != nil {
+			tb.Errorf("stopping server: %v", err)
+		}
+	}
+}
+
+// loadClient sends scenario requests over a pool of keep-alive connections
+type loadClient struct {
+	client *http.Client
+	url    string
+	sc     scenario
+}
+
+func newLoadClient(baseURL string, sc scenario, clients int) *loadClient {
+	transport := &http.Transport{
+		MaxIdleConns:        clients,
+		MaxIdleConnsPerHost: clients,
+		MaxConnsPerHost:     clients,
+		DisableCompression:  true,
+	}
+	return &loadClient{
+		client: &http.Client{Transport: transport},
+		url:    baseURL + sc.target,
+		sc:     sc,
+	}
+}
+
+// send sends one scenario request; the caller must close the response body
+func (c *loadClient) send() (*http.Response, error) {
+	var body io.Reader
+	if c.sc.body != nil {
+		body = bytes.NewReader(c.sc.body)
+	}
+
+	req, err := http.NewRequest(c.sc.method, c.url, body)
+	if err != nil {
+		return nil, err
+	}
+	if c.sc.body != nil {
+		req.Header.Set("Content-Type", c.sc.bodyType())
+	}
+	for key, values := range c.sc.header {
+		req.Header[key] = values
+	}
+
+	return c.client.Do(req)
+}
+
+// do sends one request and drains the response so its connection can be reused
+func (c *loadClient) do() error {
+	resp, err := c.send()
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if _, err := io.Copy(io.Discard, resp.Body); err != nil {
+		return err
+	}
+	if resp.StatusCode != c.sc.want.status {
+		return errUnexpectedStatus
+	}
+	return nil
+}
+
+func (c *loadClient) close() {
+	c.client.CloseIdleConnections()
+}
+
+var errUnexpectedStatus = errors.New("unexpected status code")
+
+// loadResult summarizes one load run
+type loadResult struct {
+	latency *histogram
+	errors  int
+	elapsed time.Duration
+}
+
+// runClosedLoop sends n requests from clients workers, each waiting for its response before sending the next
+func runClosedLoop(c *loadClient, n, clients int) loadResult {
+	var (
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+		result = loadResult{latency: newHistogram()}
+		next   = make(chan struct{}, n)
+	)
+	for i := 0; i < n; i++ {
+		next <- struct{}{}
+	}
+	close(next)
+
+	start := time.Now()
+	for i := 0; i < clients; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			h, errs := newHistogram(), 0
+			for range next {
+				sent := time.Now()
+				if err := c.do(); err != nil {
+					errs++
+				}
+				h.Record(time.Since(sent))
+			}
+
+			mu.Lock()
+			result.latency.Merge(h)
+			result.errors += errs
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	result.elapsed = time.Since(start)
+
+	return result
+}
+
+// runOpenLoop schedules n requests at a constant rate regardless of how fast responses arrive.
+// Latency is measured from each request's scheduled send time, so time spent waiting for a free
+// client is included instead of silently lowering the offered load (coordinated omission).
+func runOpenLoop(c *loadClient, n, clients, rate int) loadResult {
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		result   = loadResult{latency: newHistogram()}
+		interval = time.Second / time.Duration(rate)
+		due      = make(chan time.Time, n)
+	)
+
+	start := time.Now()
+	for i := 0; i < clients; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			h, errs := newHistogram(), 0
+			for scheduled := range due {
+				if err := c.do(); err != nil {
+					errs++
+				}
+				h.Record(time.Since(scheduled))
+			}
+
+			mu.Lock()
+			result.latency.Merge(h)
+			result.errors += errs
+			mu.Unlock()
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		scheduled := start.Add(time.Duration(i) * interval)
+		time.Sleep(time.Until(scheduled))
+		due <- scheduled
+	}
+	close(due)
+
+	wg.Wait()
+	result.elapsed = time.Since(start)
+
+	return result
+}
+
+// report attaches throughput and latency percentiles to the benchmark output
+func (r loadResult) report(b *testing.B) {
+	b.ReportMetric(float64(r.latency.Count())/r.elapsed.Seconds(), "req/s")
+	b.ReportMetric(float64(r.latency.Percentile(50).Nanoseconds()), "p50-ns")
+	b.ReportMetric(float64(r.latency.Percentile(90).Nanoseconds()), "p90-ns")
+	b.ReportMetric(float64(r.latency.Percentile(99).Nanoseconds()), "p99-ns")
+	b.ReportMetric(float64(r.latency.Percentile(99.9).Nanoseconds()), "p99.9-ns")
+	if r.errors > 0 {
+		b.Errorf("%d of %d requests failed", r.errors, r.latency.Count())
+	}
+}
+
+// loadTarget serves f on a loopback socket and returns a client sending sc to it, and a function that stops both
+type loadTarget func(tb testing.TB, f Framework, sc scenario) (*loadClient, func())
+
+// http1Target serves f over HTTP/1.1 through a pool of -load.clients keep-alive connections
+func http1Target(tb testing.TB, f Framework, sc scenario) (*loadClient, func()) {
+	baseURL, stop := startServer(tb, f)
+	c := newLoadClient(baseURL, sc, *loadClients)
+	return c, func() {
+		c.close()
+		stop()
+	}
+}
+
+// checkLoadFlags rejects load settings the benchmarks cannot run with, such as a zero rate,
+// which has no interval between requests
+func checkLoadFlags(clients, rate, streams int) error {
+	for _, f := range []struct {
+		name  string
+		value int
+	}{
+		{"-load.clients", clients},
+		{"-load.rate", rate},
+		{"-load.streams", streams},
+	} {
+		if f.value <= 0 {
+			return fmt.Errorf("%s must be greater than 0, got %d", f.name, f.value)
+		}
+	}
+	return nil
+}
+
+// runLoad serves the scenario on a loopback socket for every framework and drives it with run
+func runLoad(b *testing.B, target loadTarget, run func(c *loadClient, n int) loadResult) {
+	if !*loadEnabled {
+		b.Skip("real-socket load benchmarks are disabled; enable them with -load")
+	}
+	if err := checkLoadFlags(*loadClients, *loadRate, *loadStreams); err != nil {
+		b.Fatal(err)
+	}
+	defer silenceStdout(b)()
+
+	for _, fw := range frameworks {
+		if fw.inProcessOnly {
+			continue
+		}
+		b.Run(fw.name, func(b *testing.B) {
+			for _, sc := range scenarios {
+				b.Run(sc.name, func(b *testing.B) {
+					sc := sc.forFramework(fw.name)
+					if sc.want.panics {
+						b.Skip("the request panics; net/http logs it and drops the connection")
+					}
+					f := fw.new()
+					sc.routes(f)
+					c, stop := target(b, f, sc)
+					defer stop()
+
+					// Warm up once so lazily built routers are ready before concurrent use
+					if err := c.do(); err != nil {
+						b.Fatalf("warm-up request: %v", err)
+					}
+
+					b.ResetTimer()
+					run(c, b.N).report(b)
+				})
+			}
+		})
+	}
+}
+
+func TestCheckLoadFlags(t *testing.T) {
+	tests := []struct {
+		name                   string
+		clients, rate, streams int
+		wantErr                string
+	}{
+		{"defaults", 32, 5000, 100, ""},
+		{"zero rate", 32, 0, 100, "-load.rate must be greater than 0, got 0"},
+		{"negative clients", -1, 5000, 100, "-load.clients must be greater than 0, got -1"},
+		{"zero streams", 32, 5000, 0, "-load.streams must be greater than 0, got 0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkLoadFlags(tt.clients, tt.rate, tt.streams)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// BenchmarkLoadClosedLoop measures throughput and latency with -load.clients clients sending back to back
+func BenchmarkLoadClosedLoop(b *testing.B) {
+	runLoad(b, http1Target, func(c *loadClient, n int) loadResult {
+		return runClosedLoop(c, n, *loadClients)
+	})
+}
+
+// BenchmarkLoadOpenLoop measures latency under a constant offered load of -load.rate requests per second
+func BenchmarkLoadOpenLoop(b *testing.B) {
+	runLoad(b, http1Target, func(c *loadClient, n int) loadResult {
+		return runOpenLoop(c, n, *loadClients, *loadRate)
+	})
+}
@@ -19,11 +19,11 @@ func useRegex(pattern, text string) bool {
 type Product struct {
 	ID          int      `json:"id"`
 	Name        string   `json:"name" v:"required;minlen=3;maxlen=100;alphanumeric"`
-	Description string   `json:"description" v:"required;"`
+	Description string   `json:"description" v:"required;minlen=10"`
 	Price       float64  `json:"price" v:"required;gt=0;lte=999999.99"`
 	Category    string   `json:"category" v:"required;oneof=electronics clothing books home sports"`
 	SKU         string   `json:"sku" v:"required;regex=^[A-Z]{3}\\d{5}$"`
-	InStock     bool     `json:"in_stock" v:"required"`
+	InStock     bool     `json:"in_stock"` // required would reject false, the zero value
 	Tags        []string `json:"tags" v:"required;minlen=1;maxlen=5"`
 }
 
@@ -33,30 +33,90 @@ type User struct {
 	Email string `json:"email"`
 }
 
-func main() {
+// RegisterRequest is the body of POST /users/register. Each password rule checks one character
+// class; website and ssn are optional, so their patterns also match an empty value.
+type RegisterRequest struct {
+	Username    string   `json:"username" v:"required;len=5;alphanumeric"`
+	Email       string   `json:"email" v:"required;email"`
+	Password    string   `json:"password" v:"required;minlen=8;maxlen=50;regex=[A-Z];regex=[a-z];regex=[0-9];regex=[^A-Za-z0-9]"`
+	FirstName   string   `json:"first_name" v:"required;minlen=2;maxlen=50;alpha"`
+	LastName    string   `json:"last_name" v:"required;minlen=2;maxlen=50;alpha"`
+	Age         int      `json:"age" v:"required;gte=18;lte=120"`
+	Phone       string   `json:"phone" v:"required;regex=^\\+?[1-9]\\d{1,14}$"`
+	Address     string   `json:"address" v:"required;minlen=5;maxlen=200"`
+	ZipCode     string   `json:"zip_code" v:"required;regex=^\\d{5}(-\\d{4})?$"`
+	Role        string   `json:"role" v:"required;oneof=admin user moderator guest"`
+	Website     string   `json:"website" v:"regex=^(https?://\\S+)?$"`
+	Bio         string   `json:"bio" v:"maxlen=500"`
+	AcceptTerms bool     `json:"accept_terms" v:"eq=true"`
+	Salary      float64  `json:"salary" v:"gte=0;lte=10000000"`
+	Skills      []string `json:"skills" v:"required;minlen=1;maxlen=10"`
+	CountryCode string   `json:"country_code" v:"required;len=2;regex=^[A-Z]{2}$"`
+	SSN         string   `json:"ssn" v:"regex=^(\\d{3}-\\d{2}-\\d{4})?$"`
+}
+
+// ValidationTestRequest is the body of POST /validate/test, one field of each JSON kind
+type ValidationTestRequest struct {
+	TestField    string `json:"test_field" v:"required;maxlen=100"`
+	NumberField  int    `json:"number_field" v:"gte=0"`
+	BooleanField bool   `json:"boolean_field"`
+}
+
+// newApp builds the sample application with all of its routes registered, serving the products
+// held by products
+func newApp(products *ProductStore) *tree.Mux {
 	app := tree.InitMux()
 
-	// POST endpoint for creating a product with advanced validation
-	app.POST("/product", func(c *tree.Ctx) error {
-		var product Product
+	registerProductRoutes(app, products)
+	registerSearchRoutes(app, products)
 
-		// Bind JSON from request body to Product struct
-		if err := c.BindJSON(&product); err != nil {
-			return c.SendJSON(tree.J{
-				"error":   "Invalid JSON format",
-				"details": err.Error(),
-			}, http.StatusBadRequest)
+	// Registration with every rule of RegisterRequest; the password is never echoed back
+	app.POST("/users/register", func(c *tree.Ctx) error {
+		body, err := c.Body()
+		if err != nil {
+			return sendError(c, unreadableBody(err))
 		}
 
-		// Simulate saving the product (in real app, save to database)
-		product.ID = 12345 // Auto-generated ID
+		var req RegisterRequest
+		if err := decodeValid(body, &req); err != nil {
+			return sendError(c, err)
+		}
 
 		return c.SendJSON(tree.J{
-			"message": "Product created successfully",
-			"product": product,
+			"message": "User registered successfully",
+			"user": tree.J{
+				"username":     req.Username,
+				"email":        req.Email,
+				"first_name":   req.FirstName,
+				"last_name":    req.LastName,
+				"role":         req.Role,
+				"country_code": req.CountryCode,
+			},
 		}, http.StatusCreated)
 	})
 
+	// Echoes a body that passes ValidationTestRequest's rules
+	app.POST("/validate/test", func(c *tree.Ctx) error {
+		body, err := c.Body()
+		if err != nil {
+			return sendError(c, unreadableBody(err))
+		}
+
+		var req ValidationTestRequest
+		if err := decodeValid(body, &req); err != nil {
+			return sendError(c, err)
+		}
+
+		return c.SendJSON(tree.J{
+			"valid": true,
+			"data":  req,
+		}, http.StatusOK)
+	})
+
+	app.GET("/health", func(ctx *tree.Ctx) error {
+		return ctx.SendJSON(tree.J{"status": "ok"}, http.StatusOK)
+	})
+
 	// Additional regex validation endpoint for phone numbers
 	app.GET("/validate/phone/:|^\\+?[1-9]\\d{1,14}$|", func(ctx *tree.Ctx) error {
 		phone, err := ctx.RegexURLParam(1)
@@ -124,31 +184,6 @@ func main() {
 		}, http.StatusOK)
 	})
 
-	// GET endpoint for retrieving a product
-	app.GET("/product/:id", func(c *tree.Ctx) error {
-		id, err := c.GetURLParam("id")
-		if err != nil {
-			return c.SendString("Invalid ID", http.StatusBadRequest)
-		}
-
-		// Mock product data
-		product := Product{
-			ID:          123,
-			Name:        "SampleProduct123",
-			Description: "This is a sample product description with enough characters",
-			Price:       299.99,
-			Category:    "electronics",
-			SKU:         "ABC12345",
-			InStock:     true,
-			Tags:        []string{"sample", "electronics", "gadget"},
-		}
-
-		return c.SendJSON(tree.J{
-			"id":      id,
-			"product": product,
-		}, http.StatusOK)
-	})
-
 	// Simple GET handler
 	app.GET("/", func(ctx *tree.Ctx) error {
 		return ctx.SendString("Hello, Tree Framework!", http.StatusOK)
@@ -199,17 +234,24 @@ func main() {
 		}, http.StatusOK)
 	})
 
-	// Query parameters
-	app.GET("/search", func(ctx *tree.Ctx) error {
-		query, _ := ctx.GetQuery("q")
-		limit, _ := ctx.GetQuery("limit")
+	return app
+}
 
-		return ctx.SendJSON(tree.J{
-			"query":   query,
-			"limit":   limit,
-			"results": "Sample search results",
-		}, http.StatusOK)
-	})
+// sampleProduct is created at startup so the catalog is not empty
+var sampleProduct = Product{
+	Name:        "SampleProduct123",
+	Description: "This is a sample product description with enough characters",
+	Price:       299.99,
+	Category:    "electronics",
+	SKU:         "ABC12345",
+	InStock:     true,
+	Tags:        []string{"sample", "electronics", "gadget"},
+}
 
-	app.StartExecuting()
+func main() {
+	products := NewProductStore()
+	if _, err := products.Create(sampleProduct); err != nil {
+		panic(err)
+	}
+	newApp(products).StartExecuting()
 }
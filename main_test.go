@@ -1,252 +1,195 @@
 package main
 
 import (
-	"bytes"
-	"encoding/json"
+	"mime/multipart"
 	"net/http"
-	"net/http/httptest"
-	"strconv"
-	"testing"
+	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/catalinfl/tree-framework"
 )
 
-// setupApp creates a new app instance for benchmarking
-func setupApp() *tree.Mux {
-	app := tree.InitMux()
+// treeFramework adapts tree.Mux to the Framework interface
+type treeFramework struct {
+	mux *tree.Mux
 
-	// Simple GET handler
-	app.GET("/", func(ctx *tree.Ctx) error {
-		return ctx.SendString("Hello, Tree Framework!", http.StatusOK)
-	})
-
-	// JSON response handler
-	app.GET("/user/:id", func(ctx *tree.Ctx) error {
-		id, err := ctx.GetURLParam("id")
-		if err != nil {
-			return ctx.SendString("Invalid ID", http.StatusBadRequest)
-		}
-		user := User{
-			ID:    1,
-			Name:  "John Doe",
-			Email: "john@example.com",
-		}
-		return ctx.SendJSON(tree.J{
-			"id":   id,
-			"user": user,
-		}, http.StatusOK)
-	})
-
-	// POST handler with JSON body
-	app.POST("/users", func(ctx *tree.Ctx) error {
-		var user User
-		if err := ctx.BindJSON(&user); err != nil {
-			return ctx.SendJSON(tree.J{"error": "Invalid JSON"}, http.StatusBadRequest)
-		}
-
-		user.ID = 123
-		return ctx.SendJSON(tree.J{
-			"id":    user.ID,
-			"name":  user.Name,
-			"email": user.Email,
-		}, http.StatusCreated)
-	})
-
-	// Multiple route parameters
-	app.GET("/users/:id/posts/:postId", func(ctx *tree.Ctx) error {
-		userID, _ := ctx.GetURLParam("id")
-		postID, _ := ctx.GetURLParam("postId")
-
-		return ctx.SendJSON(tree.J{
-			"userId": userID,
-			"postId": postID,
-		}, http.StatusOK)
-	})
-
-	// Query parameters
-	app.GET("/search", func(ctx *tree.Ctx) error {
-		query, _ := ctx.GetQuery("q")
-		limit, _ := ctx.GetQuery("limit")
-
-		return ctx.SendJSON(tree.J{
-			"query": query,
-			"limit": limit,
-		}, http.StatusOK)
-	})
-
-	return app
+	middlewares []MiddlewareFunc
+	prefixes    map[string]bool // top-level path segments the middlewares are registered on
 }
 
-// Benchmark simple GET request
-func BenchmarkSimpleGET(b *testing.B) {
-	app := setupApp()
-	req := httptest.NewRequest("GET", "/", nil)
-
-	b.ResetTimer()
-	b.ReportAllocs()
+func newTreeFramework() Framework {
+	return &treeFramework{mux: tree.InitMux(), prefixes: make(map[string]bool)}
+}
 
-	for i := 0; i < b.N; i++ {
-		w := httptest.NewRecorder()
-		app.ServeHTTP(w, req)
-	}
+// Use records m for the routes registered after it. tree only has path-scoped middleware, and
+// USE("") is stored as "/", which matches the root path alone, so Handle registers every middleware
+// under the first segment of each new route instead.
+func (f *treeFramework) Use(m MiddlewareFunc) {
+	f.middlewares = append(f.middlewares, m)
 }
 
-// Benchmark GET request with route parameter
-func BenchmarkGetWithParam(b *testing.B) {
-	app := setupApp()
-	req := httptest.NewRequest("GET", "/user/123", nil)
+// useOn registers the middlewares on the first segment of path, once per segment
+func (f *treeFramework) useOn(path string) {
+	if len(f.middlewares) == 0 {
+		return
+	}
 
-	b.ResetTimer()
-	b.ReportAllocs()
+	prefix := "/" + strings.SplitN(strings.TrimPrefix(path, "/"), "/", 2)[0]
+	if f.prefixes[prefix] {
+		return
+	}
+	f.prefixes[prefix] = true
 
-	for i := 0; i < b.N; i++ {
-		w := httptest.NewRecorder()
-		app.ServeHTTP(w, req)
+	for _, m := range f.middlewares {
+		f.mux.USE(prefix, treeMiddleware(m))
 	}
 }
 
-// Benchmark GET request with multiple route parameters
-func BenchmarkGetWithMultipleParams(b *testing.B) {
-	app := setupApp()
-	req := httptest.NewRequest("GET", "/users/123/posts/456", nil)
+func (f *treeFramework) GET(path string, h HandlerFunc) {
+	f.Handle(http.MethodGet, path, h)
+}
 
-	b.ResetTimer()
-	b.ReportAllocs()
+func (f *treeFramework) POST(path string, h HandlerFunc) {
+	f.Handle(http.MethodPost, path, h)
+}
 
-	for i := 0; i < b.N; i++ {
-		w := httptest.NewRecorder()
-		app.ServeHTTP(w, req)
+func (f *treeFramework) Handle(method, path string, h HandlerFunc) {
+	f.useOn(path)
+
+	switch method {
+	case http.MethodGet:
+		f.mux.GET(path, treeHandler(h))
+	case http.MethodPost:
+		f.mux.POST(path, treeHandler(h))
+	case http.MethodPut:
+		f.mux.PUT(path, treeHandler(h))
+	case http.MethodPatch:
+		f.mux.PATCH(path, treeHandler(h))
+	case http.MethodDelete:
+		f.mux.DELETE(path, treeHandler(h))
+	default:
+		panic("tree adapter: unsupported method " + method)
 	}
 }
 
-// Benchmark GET request with query parameters
-func BenchmarkGetWithQueryParams(b *testing.B) {
-	app := setupApp()
-	req := httptest.NewRequest("GET", "/search?q=golang&limit=10", nil)
-
-	b.ResetTimer()
-	b.ReportAllocs()
-
-	for i := 0; i < b.N; i++ {
-		w := httptest.NewRecorder()
-		app.ServeHTTP(w, req)
-	}
+// Static serves files through Ctx.SendFile, tree's only file helper. tree has no catch-all
+// parameter, so only files directly under root are reachable. SendFile is http.ServeFile,
+// which lists directories, so they are answered with a 404 first.
+func (f *treeFramework) Static(prefix, root string) {
+	f.useOn(prefix)
+	f.mux.GET(prefix+"/:file", func(ctx *tree.Ctx) error {
+		name, _ := ctx.GetURLParam("file")
+		path := filepath.Join(root, name)
+		if info, err := os.Stat(path); err == nil && info.IsDir() {
+			return ctx.SendString("404 page not found", http.StatusNotFound)
+		}
+		return ctx.SendFile(path)
+	})
 }
 
-// Benchmark POST request with JSON payload
-func BenchmarkPostWithJSON(b *testing.B) {
-	app := setupApp()
+func (f *treeFramework) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mux.ServeHTTP(w, r)
+}
 
-	user := User{
-		Name:  "Test User",
-		Email: "test@example.com",
+func treeHandler(h HandlerFunc) tree.CtxFunc {
+	return func(ctx *tree.Ctx) error {
+		return h(treeContext{ctx: ctx})
 	}
+}
 
-	jsonData, _ := json.Marshal(user)
-
-	b.ResetTimer()
-	b.ReportAllocs()
-
-	for i := 0; i < b.N; i++ {
-		req := httptest.NewRequest("POST", "/users", bytes.NewBuffer(jsonData))
-		req.Header.Set("Content-Type", "application/json")
-		w := httptest.NewRecorder()
-		app.ServeHTTP(w, req)
+// treeMiddleware runs m as a tree middleware. In tree's default mode Ctx.Next only runs the next
+// middleware: the handler always runs afterwards, in a fresh Ctx, even if a middleware stopped the chain.
+func treeMiddleware(m MiddlewareFunc) tree.CtxFunc {
+	return func(ctx *tree.Ctx) error {
+		return m(treeContext{ctx: ctx}, ctx.Next)
 	}
 }
 
-// Benchmark routing performance with different number of routes
-func BenchmarkRouting10Routes(b *testing.B) {
-	benchmarkRouting(b, 10)
+// treeContext implements Context on top of *tree.Ctx
+type treeContext struct {
+	ctx *tree.Ctx
 }
 
-func BenchmarkRouting100Routes(b *testing.B) {
-	benchmarkRouting(b, 100)
+func (c treeContext) Param(name string) string {
+	value, _ := c.ctx.GetURLParam(name)
+	return value
 }
 
-func BenchmarkRouting1000Routes(b *testing.B) {
-	benchmarkRouting(b, 1000)
+func (c treeContext) RegexParam(index int) (string, error) {
+	return c.ctx.RegexURLParam(index)
 }
 
-func benchmarkRouting(b *testing.B, numRoutes int) {
-	app := tree.InitMux()
+func (c treeContext) Query(name string) string {
+	value, _ := c.ctx.GetQuery(name)
+	return value
+}
 
-	// Create multiple routes
-	for i := 0; i < numRoutes; i++ {
-		route := "/route" + strconv.Itoa(i)
-		app.GET(route, func(ctx *tree.Ctx) error {
-			return ctx.SendString("Route "+strconv.Itoa(i), http.StatusOK)
-		})
-	}
+func (c treeContext) BindJSON(v any) error {
+	return c.ctx.BindJSON(v)
+}
 
-	// Test the last route (worst case scenario)
-	req := httptest.NewRequest("GET", "/route"+strconv.Itoa(numRoutes-1), nil)
+func (c treeContext) BindForm(v any) error {
+	return c.ctx.BindForm(v)
+}
 
-	b.ResetTimer()
-	b.ReportAllocs()
+func (c treeContext) BindQuery(v any) error {
+	return c.ctx.BindQuery(v)
+}
 
-	for i := 0; i < b.N; i++ {
-		w := httptest.NewRecorder()
-		app.ServeHTTP(w, req)
+// FormFile closes the file tree's FormFile opens, since handlers open it from the header
+func (c treeContext) FormFile(name string) (*multipart.FileHeader, error) {
+	file, err := c.ctx.FormFile(name)
+	if err != nil {
+		return nil, err
 	}
+	file.MultipartFile.Close()
+	return file.MultipartFileHeader, nil
 }
 
-// Benchmark concurrent requests
-func BenchmarkConcurrentRequests(b *testing.B) {
-	app := setupApp()
-	req := httptest.NewRequest("GET", "/", nil)
-
-	b.ResetTimer()
-	b.ReportAllocs()
-
-	b.RunParallel(func(pb *testing.PB) {
-		for pb.Next() {
-			w := httptest.NewRecorder()
-			app.ServeHTTP(w, req)
-		}
-	})
+func (c treeContext) JSON(code int, body H) error {
+	return c.ctx.SendJSON(tree.J(body), code)
 }
 
-// Benchmark memory allocations for different payload sizes
-func BenchmarkSmallPayload(b *testing.B) {
-	benchmarkPayloadSize(b, 100)
+func (c treeContext) String(code int, s string) error {
+	c.ctx.SetHeader("Content-Type", "text/plain; charset=utf-8")
+	return c.ctx.SendString(s, code)
 }
 
-func BenchmarkMediumPayload(b *testing.B) {
-	benchmarkPayloadSize(b, 1024)
+func (c treeContext) Method() string {
+	return c.ctx.GetMethod()
 }
 
-func BenchmarkLargePayload(b *testing.B) {
-	benchmarkPayloadSize(b, 10240)
+func (c treeContext) Path() string {
+	return c.ctx.Path()
 }
 
-func benchmarkPayloadSize(b *testing.B, size int) {
-	app := tree.InitMux()
+func (c treeContext) Header(name string) string {
+	value, _ := c.ctx.GetHeader(name)
+	return value
+}
 
-	app.POST("/data", func(ctx *tree.Ctx) error {
-		// Read and echo back the data
-		var data map[string]interface{}
-		if err := ctx.BindJSON(&data); err != nil {
-			return ctx.SendJSON(tree.J{"error": "Invalid JSON"}, http.StatusBadRequest)
-		}
-		return ctx.SendJSON(tree.J(data), http.StatusOK)
-	})
+func (c treeContext) SetHeader(name, value string) {
+	c.ctx.SetHeader(name, value)
+}
 
-	// Create payload of specified size
-	payload := make(map[string]string)
-	for i := 0; i < size/10; i++ { // Approximate size control
-		payload["key"+strconv.Itoa(i)] = "value" + strconv.Itoa(i)
-	}
+// Stream renders through tree's Ctx.Render, the only Ctx method that hands out the http.ResponseWriter
+func (c treeContext) Stream(code int, contentType string, write StreamFunc) error {
+	c.ctx.SetHeader("Content-Type", contentType)
+	return c.ctx.Render(code, treeStream{contentType: contentType, write: write})
+}
 
-	jsonData, _ := json.Marshal(payload)
+// treeStream is a render.Render running a StreamFunc
+type treeStream struct {
+	contentType string
+	write       StreamFunc
+}
 
-	b.ResetTimer()
-	b.ReportAllocs()
+func (s treeStream) Render(w http.ResponseWriter) error {
+	return s.write(w, http.NewResponseController(w).Flush)
+}
 
-	for i := 0; i < b.N; i++ {
-		req := httptest.NewRequest("POST", "/data", bytes.NewBuffer(jsonData))
-		req.Header.Set("Content-Type", "application/json")
-		w := httptest.NewRecorder()
-		app.ServeHTTP(w, req)
-	}
+func (s treeStream) WritingContentType(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", s.contentType)
+	return nil
 }
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// middlewareDepths are the chain lengths measured for each kind of middleware
+var middlewareDepths = []int{1, 5, 20}
+
+// benchmarkToken is the bearer token authMiddleware accepts; every middleware scenario sends it
+const benchmarkToken = "Bearer benchmark-token"
+
+// middlewareLogger formats every line like a real access log but writes nowhere
+var middlewareLogger = log.New(io.Discard, "", log.LstdFlags|log.Lmicroseconds)
+
+func noopMiddleware(c Context, next func() error) error {
+	return next()
+}
+
+// realisticMiddlewares are cycled through to build the realistic chains
+var realisticMiddlewares = []MiddlewareFunc{
+	loggerMiddleware,
+	recoveryMiddleware,
+	requestIDMiddleware,
+	authMiddleware,
+}
+
+// loggerMiddleware logs method, path and the latency of the rest of the chain
+func loggerMiddleware(c Context, next func() error) error {
+	start := time.Now()
+	err := next()
+	middlewareLogger.Printf("%s %s %v", c.Method(), c.Path(), time.Since(start))
+	return err
+}
+
+// recoveryMiddleware turns a panic in the rest of the chain into a 500
+func recoveryMiddleware(c Context, next func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = c.String(http.StatusInternalServerError, fmt.Sprint(r))
+		}
+	}()
+	return next()
+}
+
+// requestIDMiddleware echoes the caller's X-Request-ID, or generates a random one
+func requestIDMiddleware(c Context, next func() error) error {
+	id := c.Header("X-Request-ID")
+	if id == "" {
+		var b [16]byte
+		rand.Read(b[:])
+		id = hex.EncodeToString(b[:])
+	}
+	c.SetHeader("X-Request-ID", id)
+	return next()
+}
+
+// authMiddleware rejects requests without the benchmark bearer token
+func authMiddleware(c Context, next func() error) error {
+	if subtle.ConstantTimeCompare([]byte(c.Header("Authorization")), []byte(benchmarkToken)) != 1 {
+		return c.JSON(http.StatusUnauthorized, H{"error": "unauthorized"})
+	}
+	return next()
+}
+
+// middlewareScenarios requests GET /hello through chains of increasing depth, starting with no middleware
+func middlewareScenarios() []scenario {
+	scenarios := []scenario{middlewareScenario("Middleware0", nil)}
+
+	for _, depth := range middlewareDepths {
+		noop := make([]MiddlewareFunc, depth)
+		realistic := make([]MiddlewareFunc, depth)
+		for i := 0; i < depth; i++ {
+			noop[i] = noopMiddleware
+			realistic[i] = realisticMiddlewares[i%len(realisticMiddlewares)]
+		}
+
+		scenarios = append(scenarios,
+			middlewareScenario("MiddlewareNoop"+strconv.Itoa(depth), noop),
+			middlewareScenario("MiddlewareRealistic"+strconv.Itoa(depth), realistic),
+		)
+	}
+	return scenarios
+}
+
+func middlewareScenario(name string, chain []MiddlewareFunc) scenario {
+	return scenario{
+		name: name,
+		routes: func(f Framework) {
+			for _, m := range chain {
+				f.Use(m)
+			}
+			f.GET("/hello", func(c Context) error {
+				return c.String(http.StatusOK, "Hello, World!")
+			})
+		},
+		method: http.MethodGet,
+		target: "/hello",
+		header: http.Header{"Authorization": {benchmarkToken}},
+		want:   response{status: http.StatusOK, contentType: "text/plain", body: "Hello, World!"},
+	}
+}
+
+// TestMiddlewareOrder verifies every adapter runs each middleware once, in registration order, before the handler
+func TestMiddlewareOrder(t *testing.T) {
+	for _, fw := range frameworks {
+		t.Run(fw.name, func(t *testing.T) {
+			var calls []string
+			record := func(name string) MiddlewareFunc {
+				return func(c Context, next func() error) error {
+					calls = append(calls, name)
+					return next()
+				}
+			}
+
+			f := fw.new()
+			f.Use(record("first"))
+			f.Use(record("second"))
+			f.Use(record("third"))
+			f.GET("/hello", func(c Context) error {
+				calls = append(calls, "handler")
+				return c.String(http.StatusOK, "Hello, World!")
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/hello", nil)
+			f.ServeHTTP(httptest.NewRecorder(), req)
+
+			want := []string{"first", "second", "third", "handler"}
+			if !reflect.DeepEqual(calls, want) {
+				t.Errorf("calls = %v, want %v", calls, want)
+			}
+		})
+	}
+}
+
+// TestRequestIDMiddleware verifies the realistic chain's response header reaches the client on every adapter
+func TestRequestIDMiddleware(t *testing.T) {
+	for _, fw := range frameworks {
+		t.Run(fw.name, func(t *testing.T) {
+			f := fw.new()
+			f.Use(requestIDMiddleware)
+			f.GET("/hello", func(c Context) error {
+				return c.String(http.StatusOK, "Hello, World!")
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/hello", nil)
+			req.Header.Set("X-Request-ID", "abc123")
+			w := httptest.NewRecorder()
+			f.ServeHTTP(w, req)
+
+			if got := w.Header().Get("X-Request-ID"); got != "abc123" {
+				t.Errorf("X-Request-ID = %q, want %q", got, "abc123")
+			}
+		})
+	}
+}
@@ -0,0 +1,349 @@
+package main
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"mime"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/catalinfl/tree-framework"
+)
+
+// responseError is a failure answered with its own status and JSON body
+type responseError struct {
+	status int
+	body   tree.J
+}
+
+func (e *responseError) Error() string {
+	return fmt.Sprint(e.body["error"])
+}
+
+// sendError answers a responseError with its body and a ProductStore error with its status
+func sendError(c *tree.Ctx, err error) error {
+	var re *responseError
+	if errors.As(err, &re) {
+		return c.SendJSON(re.body, re.status)
+	}
+	return c.SendJSON(tree.J{"error": err.Error()}, storeErrorStatus(err))
+}
+
+// invalidQuery is the 400 answered for a query parameter that cannot be parsed or is out of range
+func invalidQuery(err error) error {
+	return &responseError{http.StatusBadRequest, tree.J{"error": "Invalid query parameter", "details": err.Error()}}
+}
+
+// unreadableBody is the 400 answered when the request body cannot be read
+func unreadableBody(err error) error {
+	return &responseError{http.StatusBadRequest, tree.J{"error": "Invalid request body", "details": err.Error()}}
+}
+
+// registerProductRoutes adds the product catalog's REST routes, served from products
+func registerProductRoutes(app *tree.Mux, products *ProductStore) {
+	// POST endpoint for creating a product with advanced validation
+	createProduct := func(c *tree.Ctx) error {
+		body, err := c.Body()
+		if err != nil {
+			return sendError(c, unreadableBody(err))
+		}
+
+		// Decode without BindJSON, which stops at the first failing rule, then report every failure
+		var product Product
+		if err := decodeValid(body, &product); err != nil {
+			return sendError(c, err)
+		}
+
+		product, err = products.Create(product)
+		if err != nil {
+			return sendError(c, err)
+		}
+
+		return c.SendJSON(tree.J{
+			"message": "Product created successfully",
+			"product": product,
+		}, http.StatusCreated)
+	}
+	app.POST("/product", createProduct)
+	app.POST("/products", createProduct)
+
+	// GET endpoint for retrieving a product
+	app.GET("/product/:id", func(c *tree.Ctx) error {
+		id, ok := productID(c)
+		if !ok {
+			return c.SendString("Invalid ID", http.StatusBadRequest)
+		}
+
+		product, err := products.Get(id)
+		if err != nil {
+			return sendError(c, err)
+		}
+
+		return c.SendJSON(tree.J{"product": product}, http.StatusOK)
+	})
+
+	// PUT replaces the whole product; the ID in the path wins over one in the body
+	app.PUT("/product/:id", func(c *tree.Ctx) error {
+		id, ok := productID(c)
+		if !ok {
+			return c.SendString("Invalid ID", http.StatusBadRequest)
+		}
+		body, err := c.Body()
+		if err != nil {
+			return sendError(c, unreadableBody(err))
+		}
+
+		var product Product
+		if err := decodeValid(body, &product); err != nil {
+			return sendError(c, err)
+		}
+
+		product, err = products.Update(id, product)
+		if err != nil {
+			return sendError(c, err)
+		}
+
+		return c.SendJSON(tree.J{
+			"message": "Product updated successfully",
+			"product": product,
+		}, http.StatusOK)
+	})
+
+	// PATCH applies a JSON Merge Patch (RFC 7396); the patched product must pass every rule
+	app.PATCH("/product/:id", func(c *tree.Ctx) error {
+		id, ok := productID(c)
+		if !ok {
+			return c.SendString("Invalid ID", http.StatusBadRequest)
+		}
+		mediaType, _, _ := mime.ParseMediaType(c.GetRequest().Header.Get("Content-Type"))
+		if mediaType != "application/merge-patch+json" && mediaType != "application/json" {
+			return c.SendJSON(tree.J{"error": "Content-Type must be application/merge-patch+json"}, http.StatusUnsupportedMediaType)
+		}
+		body, err := c.Body()
+		if err != nil {
+			return sendError(c, unreadableBody(err))
+		}
+
+		var patch any
+		if err := json.Unmarshal(body, &patch); err != nil {
+			return c.SendJSON(tree.J{"error": "Invalid JSON format", "details": err.Error()}, http.StatusBadRequest)
+		}
+
+		product, err := products.UpdateFunc(id, func(current Product) (Product, error) {
+			data, err := json.Marshal(current)
+			if err != nil {
+				return Product{}, err
+			}
+			var doc any
+			if err := json.Unmarshal(data, &doc); err != nil {
+				return Product{}, err
+			}
+			if data, err = json.Marshal(mergePatch(doc, patch)); err != nil {
+				return Product{}, err
+			}
+
+			// Decode the whole patched document into a zero product, so removed members are cleared
+			var patched Product
+			if err := decodeValid(data, &patched); err != nil {
+				return Product{}, err
+			}
+			return patched, nil
+		})
+		if err != nil {
+			return sendError(c, err)
+		}
+
+		return c.SendJSON(tree.J{
+			"message": "Product updated successfully",
+			"product": product,
+		}, http.StatusOK)
+	})
+
+	app.DELETE("/product/:id", func(c *tree.Ctx) error {
+		id, ok := productID(c)
+		if !ok {
+			return c.SendString("Invalid ID", http.StatusBadRequest)
+		}
+		if err := products.Delete(id); err != nil {
+			return sendError(c, err)
+		}
+		return c.Status(http.StatusNoContent)
+	})
+
+	// GET /products lists the catalog one page at a time, filtered and sorted by query parameters
+	app.GET("/products", func(c *tree.Ctx) error {
+		query, err := parseProductListQuery(c.GetRequest().URL.Query())
+		if err != nil {
+			return sendError(c, invalidQuery(err))
+		}
+
+		matches := products.List(query.match)
+		if query.sortBy != nil {
+			slices.SortFunc(matches, query.compare)
+		}
+
+		start := min((query.page-1)*query.limit, len(matches))
+		end := min(start+query.limit, len(matches))
+
+		return c.SendJSON(tree.J{
+			"products":    matches[start:end],
+			"page":        query.page,
+			"limit":       query.limit,
+			"total":       len(matches),
+			"total_pages": (len(matches) + query.limit - 1) / query.limit,
+		}, http.StatusOK)
+	})
+}
+
+// productID parses the :id route parameter
+func productID(c *tree.Ctx) (int, bool) {
+	param, err := c.GetURLParam("id")
+	if err != nil {
+		return 0, false
+	}
+	id, err := strconv.Atoi(param)
+	return id, err == nil && id > 0
+}
+
+// mergePatch applies an RFC 7396 JSON Merge Patch to target, both decoded with encoding/json:
+// null members of the patch remove the member, objects are merged recursively and any other
+// value replaces the target
+func mergePatch(target, patch any) any {
+	patchObject, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+
+	targetObject, ok := target.(map[string]any)
+	if !ok {
+		targetObject = make(map[string]any)
+	}
+	for name, value := range patchObject {
+		if value == nil {
+			delete(targetObject, name)
+		} else {
+			targetObject[name] = mergePatch(targetObject[name], value)
+		}
+	}
+	return targetObject
+}
+
+const (
+	defaultPageLimit = 20
+	maxPageLimit     = 100
+)
+
+// productSortFields are the fields GET /products sorts by; ties keep ID order
+var productSortFields = map[string]func(a, b Product) int{
+	"id":       func(a, b Product) int { return cmp.Compare(a.ID, b.ID) },
+	"name":     func(a, b Product) int { return cmp.Compare(a.Name, b.Name) },
+	"price":    func(a, b Product) int { return cmp.Compare(a.Price, b.Price) },
+	"category": func(a, b Product) int { return cmp.Compare(a.Category, b.Category) },
+}
+
+// productListQuery is the parsed query string of GET /products
+type productListQuery struct {
+	page, limit int
+
+	category string
+	tag      string
+	inStock  *bool
+	minPrice *float64
+	maxPrice *float64
+
+	sortBy func(a, b Product) int // nil keeps ID order
+	desc   bool
+}
+
+// parseProductListQuery parses page, limit, category, in_stock, min_price, max_price, tag and
+// sort; sort names a field of productSortFields, prefixed with - for descending order
+func parseProductListQuery(values url.Values) (productListQuery, error) {
+	query := productListQuery{
+		category: values.Get("category"),
+		tag:      values.Get("tag"),
+	}
+
+	var err error
+	if query.page, err = queryInt(values, "page", 1, 1, 1<<20); err != nil {
+		return query, err
+	}
+	if query.limit, err = queryInt(values, "limit", defaultPageLimit, 1, maxPageLimit); err != nil {
+		return query, err
+	}
+
+	if raw := values.Get("in_stock"); raw != "" {
+		inStock, err := strconv.ParseBool(raw)
+		if err != nil {
+			return query, fmt.Errorf("in_stock must be true or false, got %q", raw)
+		}
+		query.inStock = &inStock
+	}
+	if query.minPrice, err = queryPrice(values, "min_price"); err != nil {
+		return query, err
+	}
+	if query.maxPrice, err = queryPrice(values, "max_price"); err != nil {
+		return query, err
+	}
+	if query.minPrice != nil && query.maxPrice != nil && *query.minPrice > *query.maxPrice {
+		return query, fmt.Errorf("min_price %v is greater than max_price %v", *query.minPrice, *query.maxPrice)
+	}
+
+	if raw := values.Get("sort"); raw != "" {
+		field, desc := strings.CutPrefix(raw, "-")
+		sortBy, ok := productSortFields[field]
+		if !ok {
+			return query, fmt.Errorf("sort must be one of id, name, price, category, optionally prefixed with -, got %q", raw)
+		}
+		query.sortBy, query.desc = sortBy, desc
+	}
+	return query, nil
+}
+
+// queryInt parses an optional integer query parameter between lo and hi
+func queryInt(values url.Values, name string, def, lo, hi int) (int, error) {
+	raw := values.Get(name)
+	if raw == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < lo || n > hi {
+		return 0, fmt.Errorf("%s must be an integer between %d and %d, got %q", name, lo, hi, raw)
+	}
+	return n, nil
+}
+
+// queryPrice parses an optional non-negative price query parameter
+func queryPrice(values url.Values, name string) (*float64, error) {
+	raw := values.Get(name)
+	if raw == "" {
+		return nil, nil
+	}
+	price, err := strconv.ParseFloat(raw, 64)
+	if err != nil || price < 0 || math.IsNaN(price) || math.IsInf(price, 0) {
+		return nil, fmt.Errorf("%s must be a non-negative number, got %q", name, raw)
+	}
+	return &price, nil
+}
+
+func (q productListQuery) match(p Product) bool {
+	return (q.category == "" || p.Category == q.category) &&
+		(q.tag == "" || slices.Contains(p.Tags, q.tag)) &&
+		(q.inStock == nil || p.InStock == *q.inStock) &&
+		(q.minPrice == nil || p.Price >= *q.minPrice) &&
+		(q.maxPrice == nil || p.Price <= *q.maxPrice)
+}
+
+// compare orders by the sort field, then by ID so equal values keep a stable order
+func (q productListQuery) compare(a, b Product) int {
+	order := q.sortBy(a, b)
+	if q.desc {
+		order = -order
+	}
+	return cmp.Or(order, cmp.Compare(a.ID, b.ID))
+}
@@ -0,0 +1,282 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+// newCatalog returns a store holding five products with distinct categories, prices, stock and tags
+func newCatalog(t *testing.T) *ProductStore {
+	t.Helper()
+
+	products := NewProductStore()
+	for _, p := range []Product{
+		{Name: "Laptop", Price: 1299.99, Category: "electronics", SKU: "ELE00001", InStock: true, Tags: []string{"computer", "portable"}},
+		{Name: "Novel", Price: 14.5, Category: "books", SKU: "BOO00001", InStock: true, Tags: []string{"fiction"}},
+		{Name: "Headphones", Price: 199, Category: "electronics", SKU: "ELE00002", InStock: false, Tags: []string{"audio", "portable"}},
+		{Name: "Jacket", Price: 89.9, Category: "clothing", SKU: "CLO00001", InStock: true, Tags: []string{"winter"}},
+		{Name: "Football", Price: 25, Category: "sports", SKU: "SPO00001", InStock: false, Tags: []string{"outdoor"}},
+	} {
+		p.Description = "A product of the test catalog"
+		if _, err := products.Create(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return products
+}
+
+func TestListProducts(t *testing.T) {
+	products := newCatalog(t)
+
+	tests := []struct {
+		target string
+		status int
+		want   []int // product IDs in response order
+		total  int
+	}{
+		{"/products", http.StatusOK, []int{1, 2, 3, 4, 5}, 5},
+		{"/products?limit=2", http.StatusOK, []int{1, 2}, 5},
+		{"/products?limit=2&page=3", http.StatusOK, []int{5}, 5},
+		{"/products?limit=2&page=4", http.StatusOK, []int{}, 5},
+		{"/products?category=electronics", http.StatusOK, []int{1, 3}, 2},
+		{"/products?in_stock=false", http.StatusOK, []int{3, 5}, 2},
+		{"/products?min_price=25&max_price=199", http.StatusOK, []int{3, 4, 5}, 3},
+		{"/products?tag=portable", http.StatusOK, []int{1, 3}, 2},
+		{"/products?tag=portable&in_stock=true", http.StatusOK, []int{1}, 1},
+		{"/products?sort=price", http.StatusOK, []int{2, 5, 4, 3, 1}, 5},
+		{"/products?sort=-price&limit=2", http.StatusOK, []int{1, 3}, 5},
+		{"/products?sort=name", http.StatusOK, []int{5, 3, 4, 1, 2}, 5},
+		{"/products?sort=-category", http.StatusOK, []int{5, 1, 3, 4, 2}, 5},
+		{"/products?category=home", http.StatusOK, []int{}, 0},
+		{"/products?page=0", http.StatusBadRequest, nil, 0},
+		{"/products?limit=101", http.StatusBadRequest, nil, 0},
+		{"/products?limit=ten", http.StatusBadRequest, nil, 0},
+		{"/products?in_stock=maybe", http.StatusBadRequest, nil, 0},
+		{"/products?min_price=-1", http.StatusBadRequest, nil, 0},
+		{"/products?min_price=NaN", http.StatusBadRequest, nil, 0},
+		{"/products?min_price=200&max_price=100", http.StatusBadRequest, nil, 0},
+		{"/products?sort=sku", http.StatusBadRequest, nil, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			var body struct {
+				Products json.RawMessage `json:"products"`
+				Total    int             `json:"total"`
+				Error    string          `json:"error"`
+			}
+			w := serveApp(t, products, httptest.NewRequest(http.MethodGet, tt.target, nil), &body)
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d; body %s", w.Code, tt.status, w.Body)
+			}
+			if tt.status != http.StatusOK {
+				if body.Error == "" {
+					t.Errorf("no error in %s", w.Body)
+				}
+				return
+			}
+
+			// An empty page is [], never null
+			var page []Product
+			if !bytes.HasPrefix(body.Products, []byte("[")) || json.Unmarshal(body.Products, &page) != nil {
+				t.Fatalf("products is not an array: %s", w.Body)
+			}
+			got := []int{}
+			for _, p := range page {
+				got = append(got, p.ID)
+			}
+			if !slices.Equal(got, tt.want) || body.Total != tt.total {
+				t.Errorf("IDs = %v (total %d), want %v (total %d)", got, body.Total, tt.want, tt.total)
+			}
+		})
+	}
+}
+
+// sendProduct sends body to the product route with the given method and content type
+func sendProduct(t *testing.T, products *ProductStore, method, target, contentType, body string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	req := httptest.NewRequest(method, target, bytes.NewReader([]byte(body)))
+	req.Header.Set("Content-Type", contentType)
+	return serveApp(t, products, req, nil)
+}
+
+// errBodyRead is what the body of an unreadable request fails with
+var errBodyRead = errors.New("connection reset by peer")
+
+// TestUnreadableBody checks a body that fails mid-read is answered with a JSON 400, not an empty 200
+func TestUnreadableBody(t *testing.T) {
+	tests := []struct {
+		method, target, contentType string
+	}{
+		{http.MethodPost, "/product", "application/json"},
+		{http.MethodPost, "/products", "application/json"},
+		{http.MethodPut, "/product/1", "application/json"},
+		{http.MethodPatch, "/product/1", "application/merge-patch+json"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.target, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.target, iotest.ErrReader(errBodyRead))
+			req.Header.Set("Content-Type", tt.contentType)
+
+			var body struct {
+				Error   string `json:"error"`
+				Details string `json:"details"`
+			}
+			w := serveApp(t, newCatalog(t), req, &body)
+			if w.Code != http.StatusBadRequest || body.Error != "Invalid request body" || !strings.Contains(body.Details, errBodyRead.Error()) {
+				t.Errorf("got %d %s, want 400 Invalid request body", w.Code, w.Body)
+			}
+		})
+	}
+}
+
+func TestUpdateProduct(t *testing.T) {
+	defer silenceStdout(t)()
+
+	replacement := Product{
+		ID: 99, Name: "Tablet", Description: "A replacement product", Price: 499,
+		Category: "electronics", SKU: "ELE00003", InStock: true, Tags: []string{"computer"},
+	}
+	taken := replacement
+	taken.SKU = "BOO00001"
+
+	tests := []struct {
+		name   string
+		target string
+		body   []byte
+		status int
+	}{
+		{"replace", "/product/1", mustMarshal(replacement), http.StatusOK},
+		{"missing product", "/product/42", mustMarshal(replacement), http.StatusNotFound},
+		{"SKU of another product", "/product/1", mustMarshal(taken), http.StatusConflict},
+		{"invalid product", "/product/1", []byte(`{"name":"Tablet"}`), http.StatusUnprocessableEntity},
+		{"malformed JSON", "/product/1", []byte(`{"name":`), http.StatusBadRequest},
+		{"invalid ID", "/product/x", mustMarshal(replacement), http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			products := newCatalog(t)
+			w := sendProduct(t, products, http.MethodPut, tt.target, "application/json", string(tt.body))
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d; body %s", w.Code, tt.status, w.Body)
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+
+			// The ID comes from the path, not the body
+			want := replacement
+			want.ID = 1
+			if got, _ := products.Get(1); !slices.Equal(got.Tags, want.Tags) || got.Name != want.Name || got.ID != want.ID {
+				t.Errorf("stored %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestPatchProduct(t *testing.T) {
+	defer silenceStdout(t)()
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		status      int
+		check       func(Product) bool
+	}{
+		{"change one member", "application/merge-patch+json", `{"price":999}`, http.StatusOK,
+			func(p Product) bool { return p.Price == 999 && p.Name == "Laptop" }},
+		{"replace an array", "application/merge-patch+json", `{"tags":["gaming"]}`, http.StatusOK,
+			func(p Product) bool { return slices.Equal(p.Tags, []string{"gaming"}) }},
+		{"plain JSON content type", "application/json", `{"in_stock":false}`, http.StatusOK,
+			func(p Product) bool { return !p.InStock }},
+		{"ID cannot change", "application/merge-patch+json", `{"id":7}`, http.StatusOK,
+			func(p Product) bool { return p.ID == 1 }},
+		{"null removes a required member", "application/merge-patch+json", `{"name":null}`, http.StatusUnprocessableEntity, nil},
+		{"patched product breaks a rule", "application/merge-patch+json", `{"category":"toys"}`, http.StatusUnprocessableEntity, nil},
+		{"wrong member type", "application/merge-patch+json", `{"price":"free"}`, http.StatusBadRequest, nil},
+		{"malformed JSON", "application/merge-patch+json", `{"price":`, http.StatusBadRequest, nil},
+		{"SKU of another product", "application/merge-patch+json", `{"sku":"BOO00001"}`, http.StatusConflict, nil},
+		{"unsupported content type", "text/plain", `{"price":999}`, http.StatusUnsupportedMediaType, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			products := newCatalog(t)
+			before, _ := products.Get(1)
+
+			w := sendProduct(t, products, http.MethodPatch, "/product/1", tt.contentType, tt.body)
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d; body %s", w.Code, tt.status, w.Body)
+			}
+
+			after, _ := products.Get(1)
+			if tt.check == nil {
+				if after.Price != before.Price || after.Name != before.Name || after.SKU != before.SKU {
+					t.Errorf("failed patch changed the product to %+v", after)
+				}
+			} else if !tt.check(after) {
+				t.Errorf("patched product %+v", after)
+			}
+		})
+	}
+
+	if w := sendProduct(t, newCatalog(t), http.MethodPatch, "/product/42", "application/merge-patch+json", `{}`); w.Code != http.StatusNotFound {
+		t.Errorf("missing product: status = %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
+
+func TestDeleteProduct(t *testing.T) {
+	products := newCatalog(t)
+
+	for _, want := range []int{http.StatusNoContent, http.StatusNotFound} {
+		w := serveApp(t, products, httptest.NewRequest(http.MethodDelete, "/product/2", nil), nil)
+		if w.Code != want {
+			t.Errorf("status = %d, want %d", w.Code, want)
+		}
+	}
+	if w := serveApp(t, products, httptest.NewRequest(http.MethodGet, "/product/2", nil), nil); w.Code != http.StatusNotFound {
+		t.Errorf("deleted product: status = %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
+
+// TestMergePatch replays the examples of RFC 7396, Appendix A
+func TestMergePatch(t *testing.T) {
+	tests := []struct{ target, patch, want string }{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"a":1,"e":null}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+
+	for _, tt := range tests {
+		var target, patch any
+		json.Unmarshal([]byte(tt.target), &target)
+		json.Unmarshal([]byte(tt.patch), &patch)
+
+		if got := string(mustMarshal(mergePatch(target, patch))); got != tt.want {
+			t.Errorf("merging %s into %s = %s, want %s", tt.patch, tt.target, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,351 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"runtime/pprof"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/pprof/profile"
+)
+
+var profileDir = flag.String("profile.dir", "", "write CPU, heap and allocs profiles and tree allocation sites of every BenchmarkFrameworks pair into this directory")
+
+// treeModule is the import path allocation sites are summarized for
+const treeModule = "github.com/catalinfl/tree-framework"
+
+// treeAllocSites is the number of allocation sites listed in each summary
+const treeAllocSites = 10
+
+// startProfiles starts profiling one framework/scenario pair and returns the function that stops
+// it and writes, named after the pair inside -profile.dir:
+//   - <pair>.cpu.pprof, the CPU profile of the timed loop
+//   - <pair>.heap.pprof, the live heap once the loop is done
+//   - <pair>.allocs.pprof, only the allocations made by the loop, unlike -memprofile which
+//     accumulates every benchmark of the run
+//   - <pair>.tree-allocs.txt, the top allocation sites inside tree-framework per operation
+//
+// Allocations are sampled every runtime.MemProfileRate bytes; run with -memprofilerate=1 for exact sites.
+// A benchmark runs several times while b.N grows, and each run overwrites the files of the last one.
+func startProfiles(b *testing.B, framework, scenario string) func() {
+	b.Helper()
+
+	if err := os.MkdirAll(*profileDir, 0o755); err != nil {
+		b.Fatal(err)
+	}
+	prefix := filepath.Join(*profileDir, framework+"_"+scenario)
+
+	before, err := allocsProfile()
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	cpu, err := os.Create(prefix + ".cpu.pprof")
+	if err != nil {
+		b.Fatal(err)
+	}
+	if err := pprof.StartCPUProfile(cpu); err != nil {
+		cpu.Close()
+		b.Fatalf("starting CPU profile (-profile.dir cannot be combined with -cpuprofile): %v", err)
+	}
+
+	return func() {
+		b.StopTimer()
+		pprof.StopCPUProfile()
+		if err := cpu.Close(); err != nil {
+			b.Error(err)
+		}
+
+		after, err := allocsProfile()
+		if err != nil {
+			b.Error(err)
+			return
+		}
+		allocs, err := diffAllocs(after, before)
+		if err != nil {
+			b.Error(err)
+			return
+		}
+
+		writeFile(b, prefix+".heap.pprof", func(buf *bytes.Buffer) error {
+			return pprof.Lookup("heap").WriteTo(buf, 0)
+		})
+		writeFile(b, prefix+".allocs.pprof", func(buf *bytes.Buffer) error {
+			return allocs.Write(buf)
+		})
+		writeFile(b, prefix+".tree-allocs.txt", func(buf *bytes.Buffer) error {
+			return writeTreeAllocSites(buf, framework+"/"+scenario, allocs, b.N)
+		})
+	}
+}
+
+func writeFile(b *testing.B, path string, write func(buf *bytes.Buffer) error) {
+	var buf bytes.Buffer
+	if err := write(&buf); err != nil {
+		b.Error(err)
+		return
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		b.Error(err)
+	}
+}
+
+// allocsProfile snapshots the allocs profile of the process, cumulative since it started, as an
+// encoded profile; parsing waits until both snapshots are taken so it is not counted in between.
+// The runtime publishes allocations up to two GC cycles late, hence the two collections.
+func allocsProfile() ([]byte, error) {
+	runtime.GC()
+	runtime.GC()
+
+	var buf bytes.Buffer
+	if err := pprof.Lookup("allocs").WriteTo(&buf, 0); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// diffAllocs returns what was allocated between the before and after snapshots, merged the way
+// go tool pprof -diff_base does. Allocations of the profiling itself, such as the CPU profiler
+// compressing its output while the loop runs, are left out.
+func diffAllocs(after, before []byte) (*profile.Profile, error) {
+	p, err := profile.ParseData(after)
+	if err != nil {
+		return nil, err
+	}
+	base, err := profile.ParseData(before)
+	if err != nil {
+		return nil, err
+	}
+	base.Scale(-1)
+	diff, err := profile.Merge([]*profile.Profile{p, base})
+	if err != nil {
+		return nil, err
+	}
+
+	objects, err := diff.SampleIndexByName("alloc_objects")
+	if err != nil {
+		return nil, err
+	}
+	diff.Sample = slices.DeleteFunc(diff.Sample, func(s *profile.Sample) bool {
+		return s.Value[objects] <= 0 || fromProfiler(s)
+	})
+	return diff.Compact(), nil
+}
+
+// allocsProfileFunc is the name of allocsProfile, whose own snapshot lands between two snapshots
+var allocsProfileFunc = runtime.FuncForPC(reflect.ValueOf(allocsProfile).Pointer()).Name()
+
+// fromProfiler reports whether s was allocated for the profiling itself
+func fromProfiler(s *profile.Sample) bool {
+	for _, loc := range s.Location {
+		for _, line := range loc.Line {
+			if line.Function != nil && (line.Function.Name == allocsProfileFunc || strings.HasPrefix(line.Function.Name, "runtime/pprof.")) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// allocSite is the total allocated at one line of tree-framework
+type allocSite struct {
+	site    string
+	objects int64
+	bytes   int64
+}
+
+// treeAllocSiteTotals attributes every sample of an allocs profile to the innermost
+// tree-framework frame of its stack, so allocations made by the standard library on tree's
+// behalf count towards tree's call site. Samples without a tree-framework frame are left out.
+func treeAllocSiteTotals(p *profile.Profile) ([]allocSite, error) {
+	objects, err := p.SampleIndexByName("alloc_objects")
+	if err != nil {
+		return nil, err
+	}
+	space, err := p.SampleIndexByName("alloc_space")
+	if err != nil {
+		return nil, err
+	}
+
+	totals := make(map[string]*allocSite)
+	for _, s := range p.Sample {
+		site, ok := treeAllocSite(s)
+		if !ok {
+			continue
+		}
+		if totals[site] == nil {
+			totals[site] = &allocSite{site: site}
+		}
+		totals[site].objects += s.Value[objects]
+		totals[site].bytes += s.Value[space]
+	}
+
+	sites := make([]allocSite, 0, len(totals))
+	for _, s := range totals {
+		sites = append(sites, *s)
+	}
+	slices.SortFunc(sites, func(a, b allocSite) int {
+		return cmp.Or(cmp.Compare(b.bytes, a.bytes), strings.Compare(a.site, b.site))
+	})
+	return sites, nil
+}
+
+// treeAllocSite names the innermost tree-framework frame of s. Locations run from the leaf
+// outwards, and the lines of a location from the innermost inlined function.
+func treeAllocSite(s *profile.Sample) (string, bool) {
+	for _, loc := range s.Location {
+		for _, line := range loc.Line {
+			if line.Function == nil {
+				continue
+			}
+			// Root package functions follow the module path with a dot, subpackages with a slash
+			name, ok := strings.CutPrefix(line.Function.Name, treeModule+".")
+			if !ok {
+				name, ok = strings.CutPrefix(line.Function.Name, treeModule+"/")
+			}
+			if !ok {
+				continue
+			}
+			return fmt.Sprintf("%s (%s:%d)", name, filepath.Base(line.Function.Filename), line.Line), true
+		}
+	}
+	return "", false
+}
+
+// writeTreeAllocSites writes the top tree-framework allocation sites of an allocs profile per operation
+func writeTreeAllocSites(buf *bytes.Buffer, pair string, allocs *profile.Profile, n int) error {
+	sites, err := treeAllocSiteTotals(allocs)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(buf, "Top %d allocation sites in %s for %s, per op over %d ops (MemProfileRate %d)\n",
+		treeAllocSites, treeModule, pair, n, runtime.MemProfileRate)
+	if len(sites) == 0 {
+		buf.WriteString("no allocations inside tree-framework\n")
+		return nil
+	}
+
+	fmt.Fprintf(buf, "%12s %10s  %s\n", "B/op", "allocs/op", "site")
+	for _, s := range sites[:min(len(sites), treeAllocSites)] {
+		fmt.Fprintf(buf, "%12.1f %10.2f  %s\n", float64(s.bytes)/float64(n), float64(s.objects)/float64(n), s.site)
+	}
+	return nil
+}
+
+// allocSink keeps profiledAllocs' allocations reachable so they cannot be optimized away
+var allocSink [][]byte
+
+//go:noinline
+func profiledAllocs(n int) {
+	for i := 0; i < n; i++ {
+		allocSink = append(allocSink, make([]byte, 1024))
+	}
+}
+
+func TestDiffAllocs(t *testing.T) {
+	defer func(rate int) { runtime.MemProfileRate = rate }(runtime.MemProfileRate)
+	runtime.MemProfileRate = 1
+	allocSink = make([][]byte, 0, 100)
+
+	before, err := allocsProfile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	profiledAllocs(100)
+	after, err := allocsProfile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	allocSink = nil
+
+	diff, err := diffAllocs(after, before)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Only the allocations made between the snapshots remain
+	var objects, space int64
+	for _, s := range diff.Sample {
+		if s.Location[0].Line[0].Function.Name == "tree-framework-benchmark.profiledAllocs" {
+			objects += s.Value[0]
+			space += s.Value[1]
+		}
+	}
+	if objects != 100 || space != 100*1024 {
+		t.Errorf("profiledAllocs allocated %d objects, %d bytes; want 100, %d", objects, space, 100*1024)
+	}
+}
+
+// allocSample is an allocs profile sample whose stack holds one location per frame, leaf first
+func allocSample(frames ...profile.Line) *profile.Sample {
+	s := &profile.Sample{}
+	for _, f := range frames {
+		s.Location = append(s.Location, &profile.Location{Line: []profile.Line{f}})
+	}
+	return s
+}
+
+// frame is a stack frame of function name in file at line
+func frame(name, file string, line int64) profile.Line {
+	return profile.Line{Function: &profile.Function{Name: name, Filename: file}, Line: line}
+}
+
+func TestTreeAllocSite(t *testing.T) {
+	tests := []struct {
+		name   string
+		sample *profile.Sample
+		want   string
+		wantOK bool
+	}{
+		{
+			name: "root package",
+			sample: allocSample(
+				frame("runtime.mallocgc", "/go/src/runtime/malloc.go", 1000),
+				frame(treeModule+".(*Ctx).Body", "/mod/tree-framework/ctx.go", 120),
+				frame(treeModule+".(*Router).ServeHTTP", "/mod/tree-framework/router.go", 80),
+			),
+			want:   "(*Ctx).Body (ctx.go:120)",
+			wantOK: true,
+		},
+		{
+			name: "subpackage",
+			sample: allocSample(
+				frame("reflect.New", "/go/src/reflect/value.go", 3000),
+				frame(treeModule+"/binding.validate", "/mod/tree-framework/binding/validator.go", 42),
+				frame(treeModule+".(*Ctx).Bind", "/mod/tree-framework/ctx.go", 200),
+			),
+			want:   "binding.validate (validator.go:42)",
+			wantOK: true,
+		},
+		{
+			name: "module path prefix of another module",
+			sample: allocSample(
+				frame(treeModule+"-extras.Helper", "/mod/tree-framework-extras/helper.go", 7),
+			),
+		},
+		{
+			name: "no tree-framework frame",
+			sample: allocSample(
+				frame("runtime.mallocgc", "/go/src/runtime/malloc.go", 1000),
+				frame("main.main", "/src/main.go", 10),
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := treeAllocSite(tt.sample)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("treeAllocSite() = %q, %v; want %q, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
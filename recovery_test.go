@@ -0,0 +1,274 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+// registerFailureRoutes adds a handler returning an error and one panicking, with no recovery
+// middleware, so each framework surfaces both with its own defaults
+func registerFailureRoutes(f Framework) {
+	f.GET("/fail", func(c Context) error {
+		return errHandlerFailed
+	})
+	f.GET("/panic", func(c Context) error {
+		panic(handlerPanic)
+	})
+}
+
+// failureBehavior is what a client and the process output see when a handler fails on a real socket
+type failureBehavior struct {
+	status  int // 0 when the connection is closed without a response
+	body    string
+	logged  bool // the error or panic value appears in the process output
+	crashes bool // the process serving the socket dies, so no later request is answered
+}
+
+// failureBehaviors documents how each framework served over a socket surfaces a handler returning
+// an error and a handler panicking. tree has no centralized error handler: it discards returned
+// errors, and panics reach net/http, which logs them and closes the connection.
+var failureBehaviors = map[string]struct{ err, panic failureBehavior }{
+	"Tree": {
+		err:   failureBehavior{status: http.StatusOK},
+		panic: failureBehavior{logged: true},
+	},
+	"Gin": {
+		err:   failureBehavior{status: http.StatusOK},
+		panic: failureBehavior{logged: true},
+	},
+	// fasthttp does not recover panics: without Fiber's recover middleware, the Go runtime
+	// prints the panic and exits
+	"FiberHandler": {
+		err:   failureBehavior{status: http.StatusInternalServerError, body: errHandlerFailed.Error()},
+		panic: failureBehavior{logged: true, crashes: true},
+	},
+	"FiberAdaptor": {
+		err:   failureBehavior{status: http.StatusInternalServerError, body: errHandlerFailed.Error()},
+		panic: failureBehavior{logged: true},
+	},
+	"Beego": {
+		err:   failureBehavior{status: http.StatusInternalServerError, body: errHandlerFailed.Error()},
+		panic: failureBehavior{status: http.StatusInternalServerError, logged: true},
+	},
+	"StandardHTTP": {
+		err:   failureBehavior{status: http.StatusInternalServerError, body: errHandlerFailed.Error() + "\n"},
+		panic: failureBehavior{logged: true},
+	},
+	"StandardHTTPPatterns": {
+		err:   failureBehavior{status: http.StatusInternalServerError, body: errHandlerFailed.Error() + "\n"},
+		panic: failureBehavior{logged: true},
+	},
+}
+
+const (
+	// failureServerEnv names the framework TestFailureServer serves when run as a helper process
+	failureServerEnv = "FAILURE_SERVER_FRAMEWORK"
+	// failureRequestMarker and failureResultMarker frame each request in the helper's output;
+	// everything printed between them is what the process logged while serving it
+	failureRequestMarker = "failure-request:"
+	failureResultMarker  = "failure-result:"
+)
+
+// failureOutcome is what the helper process saw for one request
+type failureOutcome struct {
+	Path   string `json:"path"`
+	Status int    `json:"status"`
+	Body   string `json:"body"`
+	Err    string `json:"err,omitempty"`
+	Logs   string `json:"-"`
+}
+
+// failurePaths are requested in order: the failing handlers, then the error handler again on a
+// new connection to prove the server survived the panic
+var failurePaths = []string{"/fail", "/panic", "/fail"}
+
+// TestFailureServer is the helper process of TestPanicOnSocket. A panic that crashes the
+// framework's server takes this whole process down, which the parent test reports.
+func TestFailureServer(t *testing.T) {
+	name := os.Getenv(failureServerEnv)
+	if name == "" {
+		t.Skip("helper process for TestPanicOnSocket")
+	}
+
+	var fw frameworkFactory
+	for _, candidate := range frameworks {
+		if candidate.name == name {
+			fw = candidate
+		}
+	}
+	if fw.new == nil {
+		t.Fatalf("unknown framework %q", name)
+	}
+
+	f := fw.new()
+	registerFailureRoutes(f)
+	baseURL, stop := startServer(t, f)
+	defer stop()
+
+	client := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+	defer client.CloseIdleConnections()
+
+	for _, path := range failurePaths {
+		fmt.Println(failureRequestMarker, path)
+
+		outcome := failureOutcome{Path: path}
+		resp, err := client.Get(baseURL + path)
+		if err != nil {
+			outcome.Err = err.Error()
+		} else {
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			outcome.Status, outcome.Body = resp.StatusCode, string(body)
+		}
+
+		fmt.Println(failureResultMarker, string(mustMarshal(outcome)))
+	}
+}
+
+// parseFailureOutput extracts the outcome of every request the helper process completed,
+// with the lines it logged while serving each one
+func parseFailureOutput(out []byte) ([]failureOutcome, error) {
+	var (
+		outcomes []failureOutcome
+		logs     strings.Builder
+	)
+
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, failureRequestMarker):
+			logs.Reset()
+		case strings.HasPrefix(line, failureResultMarker):
+			var outcome failureOutcome
+			if err := json.Unmarshal([]byte(strings.TrimPrefix(line, failureResultMarker)), &outcome); err != nil {
+				return nil, err
+			}
+			outcome.Logs = logs.String()
+			outcomes = append(outcomes, outcome)
+		default:
+			logs.WriteString(line + "\n")
+		}
+	}
+	return outcomes, scanner.Err()
+}
+
+// checkFailure reports how an outcome differs from the documented behavior; logged is matched
+// against the value the handler failed with
+func checkFailure(outcome failureOutcome, want failureBehavior, value string) []string {
+	var problems []string
+
+	if want.status == 0 {
+		if outcome.Err == "" {
+			problems = append(problems, fmt.Sprintf("%s: want the connection closed, got %d %q", outcome.Path, outcome.Status, outcome.Body))
+		}
+	} else if outcome.Status != want.status || outcome.Body != want.body {
+		problems = append(problems, fmt.Sprintf("%s: want %d %q, got %d %q (err %q)",
+			outcome.Path, want.status, want.body, outcome.Status, outcome.Body, outcome.Err))
+	}
+
+	if logged := strings.Contains(outcome.Logs, value); logged != want.logged {
+		problems = append(problems, fmt.Sprintf("%s: want logged %v, got %v; output:\n%s", outcome.Path, want.logged, logged, outcome.Logs))
+	}
+	return problems
+}
+
+// TestPanicOnSocket serves every framework on a real socket in a helper process and verifies
+// returned errors and panics are surfaced as documented in failureBehaviors. It fails when a
+// panic crashes a process documented to survive it, or leaves the server unable to answer the
+// next request.
+func TestPanicOnSocket(t *testing.T) {
+	for _, fw := range frameworks {
+		if fw.inProcessOnly {
+			continue
+		}
+		t.Run(fw.name, func(t *testing.T) {
+			t.Parallel()
+
+			want, ok := failureBehaviors[fw.name]
+			if !ok {
+				t.Fatalf("no documented failure behavior for %s", fw.name)
+			}
+
+			cmd := exec.Command(os.Args[0], "-test.run=^TestFailureServer$", "-test.count=1")
+			cmd.Env = append(os.Environ(), failureServerEnv+"="+fw.name)
+			out, runErr := cmd.CombinedOutput()
+
+			outcomes, err := parseFailureOutput(out)
+			if err != nil {
+				t.Fatalf("parsing helper output: %v\n%s", err, out)
+			}
+			if want.panic.crashes {
+				// The process dies while serving /panic, before reporting its outcome
+				if runErr == nil || len(outcomes) != 1 {
+					t.Fatalf("want the panic to crash the process serving the socket, got %d outcomes (exit %v):\n%s", len(outcomes), runErr, out)
+				}
+				problems := checkFailure(outcomes[0], want.err, errHandlerFailed.Error())
+				if logged := strings.Contains(string(out), handlerPanic); logged != want.panic.logged {
+					problems = append(problems, fmt.Sprintf("/panic: want logged %v, got %v; output:\n%s", want.panic.logged, logged, out))
+				}
+				for _, problem := range problems {
+					t.Error(problem)
+				}
+				return
+			}
+			if runErr != nil || len(outcomes) != len(failurePaths) {
+				t.Fatalf("a handler panic crashed the process serving the socket (%v):\n%s", runErr, out)
+			}
+
+			problems := checkFailure(outcomes[0], want.err, errHandlerFailed.Error())
+			problems = append(problems, checkFailure(outcomes[1], want.panic, handlerPanic)...)
+			// The server must still answer once the panicking connection is gone
+			problems = append(problems, checkFailure(outcomes[2], want.err, errHandlerFailed.Error())...)
+			for _, problem := range problems {
+				t.Error(problem)
+			}
+		})
+	}
+}
+
+// BenchmarkFailureSurfacing measures each framework's own handling of a returned error and of a
+// panic, without recovery middleware. Panics escaping ServeHTTP are recovered the way net/http
+// does; every framework is wrapped the same way so the wrapper's cost cancels out.
+func BenchmarkFailureSurfacing(b *testing.B) {
+	defer silenceStdout(b)()
+
+	failures := []struct{ name, target string }{
+		{"Error", "/fail"},
+		{"Panic", "/panic"},
+	}
+
+	for _, fw := range frameworks {
+		b.Run(fw.name, func(b *testing.B) {
+			for _, failure := range failures {
+				b.Run(failure.name, func(b *testing.B) {
+					if fw.name == "FiberTest" && failure.name == "Panic" {
+						b.Skip("app.Test serves on its own goroutine, where fasthttp lets the panic crash the process")
+					}
+					f := fw.new()
+					registerFailureRoutes(f)
+					h := recoverHandler{f}
+
+					req := httptest.NewRequest(http.MethodGet, failure.target, nil)
+					h.ServeHTTP(httptest.NewRecorder(), req)
+
+					b.ResetTimer()
+					b.ReportAllocs()
+
+					for i := 0; i < b.N; i++ {
+						h.ServeHTTP(httptest.NewRecorder(), req)
+					}
+				})
+			}
+		})
+	}
+}
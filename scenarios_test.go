@@ -0,0 +1,411 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+// scenario is one request pattern measured against every framework
+type scenario struct {
+	name   string
+	routes func(f Framework)
+	method string
+	target string
+	body   []byte // nil for requests without a body
+	// contentType is the Content-Type of body, application/json when empty
+	contentType string
+	header      http.Header
+	parallel    bool
+	want        response
+	// wantFor overrides want for the frameworks named as keys, on paths where frameworks disagree
+	wantFor map[string]response
+}
+
+// newRequest builds the scenario request. Requests with a body must be rebuilt for every call.
+func (sc scenario) newRequest() *http.Request {
+	var body io.Reader
+	if sc.body != nil {
+		body = bytes.NewReader(sc.body)
+	}
+
+	req := httptest.NewRequest(sc.method, sc.target, body)
+	if sc.body != nil {
+		req.Header.Set("Content-Type", sc.bodyType())
+	}
+	for key, values := range sc.header {
+		req.Header[key] = values
+	}
+	return req
+}
+
+// forFramework returns sc with want replaced by the named framework's own expected response, if it has one
+func (sc scenario) forFramework(name string) scenario {
+	if want, ok := sc.wantFor[name]; ok {
+		sc.want = want
+	}
+	return sc
+}
+
+func (sc scenario) bodyType() string {
+	if sc.contentType == "" {
+		return "application/json"
+	}
+	return sc.contentType
+}
+
+// scenarios is the table every framework is benchmarked against
+var scenarios = concatScenarios([]scenario{
+	{
+		name:   "SimpleGET",
+		routes: registerSampleRoutes,
+		method: http.MethodGet,
+		target: "/hello",
+		want:   response{status: http.StatusOK, contentType: "text/plain", body: "Hello, World!"},
+	},
+	{
+		name:   "GetWithParam",
+		routes: registerSampleRoutes,
+		method: http.MethodGet,
+		target: "/user/123",
+		want: response{status: http.StatusOK, contentType: "application/json",
+			body: `{"id":"123","user":{"id":1,"name":"John Doe","email":"john@example.com"}}`},
+	},
+	{
+		name:   "GetWithMultipleParams",
+		routes: registerSampleRoutes,
+		method: http.MethodGet,
+		target: "/users/123/posts/456",
+		want:   response{status: http.StatusOK, contentType: "application/json", body: `{"userId":"123","postId":"456"}`},
+	},
+	{
+		name:   "GetWithQueryParams",
+		routes: registerSampleRoutes,
+		method: http.MethodGet,
+		target: "/search?q=golang&limit=10",
+		want:   response{status: http.StatusOK, contentType: "application/json", body: `{"query":"golang","limit":"10"}`},
+	},
+	{
+		name:   "PostWithJSON",
+		routes: registerSampleRoutes,
+		method: http.MethodPost,
+		target: "/users",
+		body:   mustMarshal(User{Name: "Test User", Email: "test@example.com"}),
+		want: response{status: http.StatusCreated, contentType: "application/json",
+			body: `{"id":123,"name":"Test User","email":"test@example.com"}`},
+	},
+	routingScenario(10),
+	routingScenario(100),
+	routingScenario(1000),
+	{
+		name:     "ConcurrentRequests",
+		routes:   registerSampleRoutes,
+		method:   http.MethodGet,
+		target:   "/hello",
+		parallel: true,
+		want:     response{status: http.StatusOK, contentType: "text/plain", body: "Hello, World!"},
+	},
+	payloadScenario("SmallPayload", 100),
+	payloadScenario("MediumPayload", 1024),
+	payloadScenario("LargePayload", 10240),
+	{
+		name:   "RegexPhoneMatch",
+		routes: registerRegexRoutes,
+		method: http.MethodGet,
+		target: "/validate/phone/+1234567890",
+		want: response{status: http.StatusOK, contentType: "application/json",
+			body: `{"valid":true,"phone":"+1234567890","message":"Valid international phone format"}`},
+	},
+	{
+		name:   "RegexPhoneReject",
+		routes: registerRegexRoutes,
+		method: http.MethodGet,
+		target: "/validate/phone/+0123456789",
+		want: response{status: http.StatusBadRequest, contentType: "application/json",
+			body: `{"valid":false,"error":"Invalid phone format","details":"regex not respected"}`},
+	},
+	{
+		name:   "RegexMultiMatch",
+		routes: registerRegexRoutes,
+		method: http.MethodGet,
+		target: "/user/john_doe123/email/john@example.com",
+		want: response{status: http.StatusOK, contentType: "application/json",
+			body: `{"valid":true,"username":"john_doe123","email":"john@example.com","message":"Both username and email are valid"}`},
+	},
+	{
+		// The username passes and the email fails, so both patterns are evaluated
+		name:   "RegexMultiReject",
+		routes: registerRegexRoutes,
+		method: http.MethodGet,
+		target: "/user/valid_user/email/invalid-email",
+		want: response{status: http.StatusBadRequest, contentType: "application/json",
+			body: `{"valid":false,"error":"Invalid email format","details":"regex not respected"}`},
+	},
+}, routeSetScenarios(), middlewareScenarios(), formScenarios(), errorScenarios())
+
+func concatScenarios(groups ...[]scenario) []scenario {
+	var all []scenario
+	for _, group := range groups {
+		all = append(all, group...)
+	}
+	return all
+}
+
+// registerSampleRoutes registers the route set shared by the basic scenarios
+func registerSampleRoutes(f Framework) {
+	// Simple GET handler. Not served on "/" because tree never attaches a handler to the root route.
+	f.GET("/hello", func(c Context) error {
+		return c.String(http.StatusOK, "Hello, World!")
+	})
+
+	// JSON response handler
+	f.GET("/user/:id", func(c Context) error {
+		user := User{
+			ID:    1,
+			Name:  "John Doe",
+			Email: "john@example.com",
+		}
+		return c.JSON(http.StatusOK, H{
+			"id":   c.Param("id"),
+			"user": user,
+		})
+	})
+
+	// POST handler with JSON body
+	f.POST("/users", func(c Context) error {
+		var user User
+		if err := c.BindJSON(&user); err != nil {
+			return c.JSON(http.StatusBadRequest, H{"error": "Invalid JSON"})
+		}
+
+		user.ID = 123
+		return c.JSON(http.StatusCreated, H{
+			"id":    user.ID,
+			"name":  user.Name,
+			"email": user.Email,
+		})
+	})
+
+	// Multiple route parameters
+	f.GET("/users/:id/posts/:postId", func(c Context) error {
+		return c.JSON(http.StatusOK, H{
+			"userId": c.Param("id"),
+			"postId": c.Param("postId"),
+		})
+	})
+
+	// Query parameters
+	f.GET("/search", func(c Context) error {
+		return c.JSON(http.StatusOK, H{
+			"query": c.Query("q"),
+			"limit": c.Query("limit"),
+		})
+	})
+}
+
+// Regex routes from main.go. Tree validates `:|pattern|` segments in RegexURLParam;
+// the other adapters match a plain parameter and validate it with a precompiled regexp.
+const (
+	phoneRoute         = "/validate/phone/:|^\\+?[1-9]\\d{1,14}$|"
+	usernameEmailRoute = "/user/:|^[a-zA-Z0-9_]{3,20}$|/email/:|^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\\.[a-zA-Z]{2,}$|"
+)
+
+// registerRegexRoutes registers the regex validated routes of main.go
+func registerRegexRoutes(f Framework) {
+	// Single regex segment
+	f.GET(phoneRoute, func(c Context) error {
+		phone, err := c.RegexParam(1)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, H{
+				"valid":   false,
+				"error":   "Invalid phone format",
+				"details": err.Error(),
+			})
+		}
+
+		return c.JSON(http.StatusOK, H{
+			"valid":   true,
+			"phone":   phone,
+			"message": "Valid international phone format",
+		})
+	})
+
+	// Multiple regex segments
+	f.GET(usernameEmailRoute, func(c Context) error {
+		username, err := c.RegexParam(1)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, H{
+				"valid":   false,
+				"error":   "Invalid username format",
+				"details": err.Error(),
+			})
+		}
+
+		email, err := c.RegexParam(2)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, H{
+				"valid":   false,
+				"error":   "Invalid email format",
+				"details": err.Error(),
+			})
+		}
+
+		return c.JSON(http.StatusOK, H{
+			"valid":    true,
+			"username": username,
+			"email":    email,
+			"message":  "Both username and email are valid",
+		})
+	})
+}
+
+// routingScenario registers numRoutes static routes and requests the last one (worst case scenario)
+func routingScenario(numRoutes int) scenario {
+	return scenario{
+		name: "Routing" + strconv.Itoa(numRoutes) + "Routes",
+		routes: func(f Framework) {
+			for i := 0; i < numRoutes; i++ {
+				message := "Route " + strconv.Itoa(i)
+				f.GET("/route"+strconv.Itoa(i), func(c Context) error {
+					return c.String(http.StatusOK, message)
+				})
+			}
+		},
+		method: http.MethodGet,
+		target: "/route" + strconv.Itoa(numRoutes-1),
+		want:   response{status: http.StatusOK, contentType: "text/plain", body: "Route " + strconv.Itoa(numRoutes-1)},
+	}
+}
+
+// payload is the body of the payload scenarios. The generated data is wrapped in a struct
+// because tree's BindJSON only accepts struct destinations.
+type payload struct {
+	Data map[string]string `json:"data"`
+}
+
+// payloadScenario echoes back a JSON object of roughly size bytes
+func payloadScenario(name string, size int) scenario {
+	data := make(map[string]string)
+	for i := 0; i < size/10; i++ { // Approximate size control
+		data["key"+strconv.Itoa(i)] = "value" + strconv.Itoa(i)
+	}
+	body := mustMarshal(payload{Data: data})
+
+	return scenario{
+		name: name,
+		routes: func(f Framework) {
+			f.POST("/data", func(c Context) error {
+				var p payload
+				if err := c.BindJSON(&p); err != nil {
+					return c.JSON(http.StatusBadRequest, H{"error": "Invalid JSON"})
+				}
+				return c.JSON(http.StatusOK, H{"data": p.Data})
+			})
+		},
+		method: http.MethodPost,
+		target: "/data",
+		body:   body,
+		want:   response{status: http.StatusOK, contentType: "application/json", body: string(body)},
+	}
+}
+
+func mustMarshal(v any) []byte {
+	data, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
+
+// BenchmarkFrameworks runs every scenario against every framework as Framework/Scenario sub-benchmarks.
+// Nothing is measured unless every framework first returns the golden response for every scenario.
+func BenchmarkFrameworks(b *testing.B) {
+	defer silenceStdout(b)()
+
+	if err := checkAllConformance(); err != nil {
+		b.Fatalf("frameworks are not doing equivalent work:\n%v", err)
+	}
+
+	for _, fw := range frameworks {
+		b.Run(fw.name, func(b *testing.B) {
+			for _, sc := range scenarios {
+				b.Run(sc.name, func(b *testing.B) {
+					runScenario(b, fw, sc)
+				})
+			}
+		})
+	}
+}
+
+func runScenario(b *testing.B, fw frameworkFactory, sc scenario) {
+	sc = sc.forFramework(fw.name)
+	f := fw.new()
+	sc.routes(f)
+
+	var h http.Handler = f
+	if sc.want.panics {
+		h = recoverHandler{f}
+	}
+	// Warm up once so lazily built routers are ready before timing and before parallel use
+	h.ServeHTTP(httptest.NewRecorder(), sc.newRequest())
+
+	// newServe returns the func serving the scenario's request once; every parallel goroutine
+	// gets its own, as prepared requests cannot be shared
+	newServe := func() (func(), error) {
+		if p, ok := f.(preparer); ok && !sc.want.panics {
+			serve, err := p.prepare(sc.newRequest())
+			if serve != nil || err != nil {
+				return serve, err
+			}
+		}
+		req := sc.newRequest()
+		return func() {
+			req := req
+			if sc.body != nil {
+				req = sc.newRequest()
+			}
+			h.ServeHTTP(httptest.NewRecorder(), req)
+		}, nil
+	}
+
+	serve, err := newServe()
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	if *profileDir != "" {
+		defer startProfiles(b, fw.name, sc.name)()
+	}
+
+	b.ResetTimer()
+	b.ReportAllocs()
+
+	if sc.parallel {
+		b.RunParallel(func(pb *testing.PB) {
+			serve, err := newServe()
+			if err != nil {
+				b.Error(err)
+				return
+			}
+			for pb.Next() {
+				serve()
+			}
+		})
+		return
+	}
+
+	for i := 0; i < b.N; i++ {
+		serve()
+	}
+}
+
+// preparer is implemented by adapters that can convert a request to their native form once,
+// outside the timed loop. prepare returns a nil serve func when the adapter converts every
+// request as part of what it measures; serve is not safe for concurrent use.
+type preparer interface {
+	prepare(r *http.Request) (serve func(), err error)
+}
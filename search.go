@@ -0,0 +1,184 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/catalinfl/tree-framework"
+)
+
+const (
+	maxSearchQueryLen = 200
+	maxSearchTerms    = 10
+	defaultSearchSize = 10
+	maxSearchSize     = 50
+	maxSearchOffset   = 10000
+)
+
+// Weights of a term found in each product field; a term matching only the start of a word counts half
+const (
+	nameWeight        = 3
+	tagWeight         = 2
+	descriptionWeight = 1
+)
+
+// SearchResult is one product matching a search, with its relevance score
+type SearchResult struct {
+	Product Product `json:"product"`
+	Score   float64 `json:"score"`
+}
+
+// searchQuery is the parsed query string of GET /search
+type searchQuery struct {
+	q      string
+	terms  []string
+	limit  int
+	offset int
+	sort   string
+	sortBy func(a, b Product) int // nil ranks by score
+	desc   bool
+}
+
+// parseSearchQuery parses q, limit, offset and sort; sort is relevance (the default) or a field
+// of productSortFields, prefixed with - for descending order
+func parseSearchQuery(values url.Values) (searchQuery, error) {
+	query := searchQuery{q: strings.TrimSpace(values.Get("q")), sort: values.Get("sort")}
+
+	switch {
+	case query.q == "":
+		return query, fmt.Errorf("q is required")
+	case len(query.q) > maxSearchQueryLen:
+		return query, fmt.Errorf("q must be at most %d bytes, got %d", maxSearchQueryLen, len(query.q))
+	}
+	query.terms = searchTerms(query.q)
+	switch {
+	case len(query.terms) == 0:
+		return query, fmt.Errorf("q must contain a letter or digit, got %q", query.q)
+	case len(query.terms) > maxSearchTerms:
+		return query, fmt.Errorf("q must have at most %d terms, got %d", maxSearchTerms, len(query.terms))
+	}
+
+	var err error
+	if query.limit, err = queryInt(values, "limit", defaultSearchSize, 1, maxSearchSize); err != nil {
+		return query, err
+	}
+	if query.offset, err = queryInt(values, "offset", 0, 0, maxSearchOffset); err != nil {
+		return query, err
+	}
+
+	if query.sort == "" {
+		query.sort = "relevance"
+	}
+	if query.sort != "relevance" {
+		field, desc := strings.CutPrefix(query.sort, "-")
+		sortBy, ok := productSortFields[field]
+		if !ok {
+			return query, fmt.Errorf("sort must be relevance or one of id, name, price, category, optionally prefixed with -, got %q", query.sort)
+		}
+		query.sortBy, query.desc = sortBy, desc
+	}
+	return query, nil
+}
+
+// searchTerms splits text into lowercase words of letters and digits, without duplicates
+func searchTerms(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	slices.Sort(words)
+	return slices.Compact(words)
+}
+
+// termScore is weight when one of words is term, half of it when a word only starts with term,
+// and 0 otherwise
+func termScore(term string, words []string, weight float64) float64 {
+	score := 0.0
+	for _, word := range words {
+		if word == term {
+			return weight
+		}
+		if strings.HasPrefix(word, term) {
+			score = weight / 2
+		}
+	}
+	return score
+}
+
+// scoreProduct adds up, for every term, its best score across name, tags and description. A
+// product matches only when every term is found, so the score is 0 as soon as one is missing.
+func scoreProduct(p Product, terms []string) float64 {
+	name, description := searchTerms(p.Name), searchTerms(p.Description)
+	tags := searchTerms(strings.Join(p.Tags, " "))
+
+	total := 0.0
+	for _, term := range terms {
+		best := max(
+			termScore(term, name, nameWeight),
+			termScore(term, tags, tagWeight),
+			termScore(term, description, descriptionWeight),
+		)
+		if best == 0 {
+			return 0
+		}
+		total += best
+	}
+	return total
+}
+
+// search returns every product matching all terms of query, ordered by its sort
+func search(products *ProductStore, query searchQuery) []SearchResult {
+	scores := make(map[int]float64)
+	matches := products.List(func(p Product) bool {
+		score := scoreProduct(p, query.terms)
+		if score > 0 {
+			scores[p.ID] = score
+		}
+		return score > 0
+	})
+
+	results := make([]SearchResult, len(matches))
+	for i, p := range matches {
+		results[i] = SearchResult{Product: p, Score: scores[p.ID]}
+	}
+
+	slices.SortFunc(results, func(a, b SearchResult) int {
+		if query.sortBy == nil {
+			return cmp.Or(cmp.Compare(b.Score, a.Score), cmp.Compare(a.Product.ID, b.Product.ID))
+		}
+		order := query.sortBy(a.Product, b.Product)
+		if query.desc {
+			order = -order
+		}
+		return cmp.Or(order, cmp.Compare(a.Product.ID, b.Product.ID))
+	})
+	return results
+}
+
+// registerSearchRoutes adds GET /search, a full-text search over the catalog held by products
+func registerSearchRoutes(app *tree.Mux, products *ProductStore) {
+	app.GET("/search", func(c *tree.Ctx) error {
+		query, err := parseSearchQuery(c.GetRequest().URL.Query())
+		if err != nil {
+			return sendError(c, invalidQuery(err))
+		}
+
+		results := search(products, query)
+		start := min(query.offset, len(results))
+		end := min(start+query.limit, len(results))
+
+		return c.SendJSON(tree.J{
+			"query":   query.q,
+			"terms":   query.terms,
+			"results": results[start:end],
+			"total":   len(results),
+			"limit":   query.limit,
+			"offset":  query.offset,
+			"sort":    query.sort,
+		}, http.StatusOK)
+	})
+}
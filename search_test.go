@@ -0,0 +1,118 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSearch(t *testing.T) {
+	products := newCatalog(t)
+
+	tests := []struct {
+		name   string
+		query  url.Values
+		want   []int // product IDs in response order
+		scores []float64
+		total  int
+	}{
+		{"name", url.Values{"q": {"laptop"}}, []int{1}, []float64{nameWeight}, 1},
+		{"case insensitive", url.Values{"q": {"LAPTOP"}}, []int{1}, []float64{nameWeight}, 1},
+		{"name prefix", url.Values{"q": {"lap"}}, []int{1}, []float64{nameWeight / 2.0}, 1},
+		{"tag", url.Values{"q": {"portable"}}, []int{1, 3}, []float64{tagWeight, tagWeight}, 2},
+		{"description", url.Values{"q": {"catalog"}}, []int{1, 2, 3, 4, 5}, nil, 5},
+		{"every term must match", url.Values{"q": {"portable audio"}}, []int{3}, []float64{2 * tagWeight}, 1},
+		{"no match", url.Values{"q": {"portable fiction"}}, []int{}, nil, 0},
+		{"ranked by score", url.Values{"q": {"p"}}, []int{1, 3, 2, 4, 5},
+			[]float64{tagWeight / 2.0, tagWeight / 2.0, descriptionWeight / 2.0, descriptionWeight / 2.0, descriptionWeight / 2.0}, 5},
+		{"best field wins", url.Values{"q": {"laptop product"}}, []int{1}, []float64{nameWeight + descriptionWeight}, 1},
+		{"sorted by price", url.Values{"q": {"catalog"}, "sort": {"-price"}}, []int{1, 3, 4, 5, 2}, nil, 5},
+		{"sorted by name", url.Values{"q": {"catalog"}, "sort": {"name"}}, []int{5, 3, 4, 1, 2}, nil, 5},
+		{"limit and offset", url.Values{"q": {"catalog"}, "limit": {"2"}, "offset": {"1"}}, []int{2, 3}, nil, 5},
+		{"offset past the end", url.Values{"q": {"catalog"}, "offset": {"10"}}, []int{}, nil, 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body struct {
+				Results []SearchResult `json:"results"`
+				Total   int            `json:"total"`
+			}
+			w := serveApp(t, products, httptest.NewRequest(http.MethodGet, "/search?"+tt.query.Encode(), nil), &body)
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d; body %s", w.Code, http.StatusOK, w.Body)
+			}
+
+			ids, scores := []int{}, []float64{}
+			for _, result := range body.Results {
+				ids = append(ids, result.Product.ID)
+				scores = append(scores, result.Score)
+			}
+			if !slices.Equal(ids, tt.want) || body.Total != tt.total {
+				t.Errorf("IDs = %v (total %d), want %v (total %d)", ids, body.Total, tt.want, tt.total)
+			}
+			if tt.scores != nil && !slices.Equal(scores, tt.scores) {
+				t.Errorf("scores = %v, want %v", scores, tt.scores)
+			}
+		})
+	}
+}
+
+func TestSearchInvalidQuery(t *testing.T) {
+	products := newCatalog(t)
+
+	tests := []struct {
+		name  string
+		query url.Values
+	}{
+		{"missing q", url.Values{}},
+		{"blank q", url.Values{"q": {"   "}}},
+		{"q without words", url.Values{"q": {"!?"}}},
+		{"q too long", url.Values{"q": {strings.Repeat("a", maxSearchQueryLen+1)}}},
+		{"too many terms", url.Values{"q": {"a b c d e f g h i j k"}}},
+		{"limit zero", url.Values{"q": {"laptop"}, "limit": {"0"}}},
+		{"limit too large", url.Values{"q": {"laptop"}, "limit": {"51"}}},
+		{"limit not a number", url.Values{"q": {"laptop"}, "limit": {"ten"}}},
+		{"negative offset", url.Values{"q": {"laptop"}, "offset": {"-1"}}},
+		{"offset not a number", url.Values{"q": {"laptop"}, "offset": {"1.5"}}},
+		{"unknown sort", url.Values{"q": {"laptop"}, "sort": {"score"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body struct {
+				Error   string `json:"error"`
+				Details string `json:"details"`
+			}
+			w := serveApp(t, products, httptest.NewRequest(http.MethodGet, "/search?"+tt.query.Encode(), nil), &body)
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("status = %d, want %d; body %s", w.Code, http.StatusBadRequest, w.Body)
+			}
+			if body.Details == "" {
+				t.Errorf("no details in %s", w.Body)
+			}
+		})
+	}
+}
+
+func TestSearchTerms(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"Gaming Laptop", []string{"gaming", "laptop"}},
+		{"  high-performance, 16GB!", []string{"16gb", "high", "performance"}},
+		{"go Go GO", []string{"go"}},
+		{"Café crème", []string{"café", "crème"}},
+		{"?!", []string{}},
+	}
+
+	for _, tt := range tests {
+		if got := searchTerms(tt.text); !slices.Equal(got, tt.want) {
+			t.Errorf("searchTerms(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
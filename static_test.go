@@ -0,0 +1,221 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// staticFiles are the generated assets, small enough for any in-memory cache up to larger than most
+var staticFiles = []struct {
+	label string
+	name  string
+	size  int
+}{
+	{"Small", "small.txt", 1 << 10},
+	{"Medium", "medium.txt", 64 << 10},
+	{"Large", "large.txt", 1 << 20},
+}
+
+const (
+	staticPrefix = "/static"
+	// staticMissDir is a directory without index.html inside the static root
+	staticMissDir = "docs"
+)
+
+// staticModTime is the modification time of every generated file, so If-Modified-Since is deterministic
+var staticModTime = time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+func staticContent(size int) []byte {
+	line := []byte("static asset line 0123456789abcdefghijklmnopqrstuvwxyz\n")
+	return bytes.Repeat(line, size/len(line)+1)[:size]
+}
+
+// writeStaticDir generates the static root in a temporary directory
+func writeStaticDir(tb testing.TB) string {
+	tb.Helper()
+
+	root := tb.TempDir()
+	write := func(name string, data []byte) {
+		path := filepath.Join(root, name)
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			tb.Fatal(err)
+		}
+		if err := os.Chtimes(path, staticModTime, staticModTime); err != nil {
+			tb.Fatal(err)
+		}
+	}
+
+	for _, file := range staticFiles {
+		write(file.name, staticContent(file.size))
+	}
+	if err := os.Mkdir(filepath.Join(root, staticMissDir), 0o755); err != nil {
+		tb.Fatal(err)
+	}
+	write(filepath.Join(staticMissDir, "readme.txt"), []byte("not an index"))
+
+	return root
+}
+
+// staticKind is the request pattern of a static case
+type staticKind int
+
+const (
+	staticGet staticKind = iota
+	staticIfModifiedSince
+	staticIfNoneMatch
+	staticRange
+	staticDirectoryMiss
+)
+
+// staticRangeLength is the number of bytes requested by the Range cases
+const staticRangeLength = 512
+
+type staticCase struct {
+	name string
+	kind staticKind
+	file string
+	size int
+}
+
+// staticCases lists every kind of request for every generated file, and the directory miss
+func staticCases() []staticCase {
+	kinds := []struct {
+		name string
+		kind staticKind
+	}{
+		{"Get", staticGet},
+		{"IfModifiedSince", staticIfModifiedSince},
+		{"IfNoneMatch", staticIfNoneMatch},
+		{"Range", staticRange},
+	}
+
+	var cases []staticCase
+	for _, k := range kinds {
+		for _, file := range staticFiles {
+			cases = append(cases, staticCase{name: k.name + file.label, kind: k.kind, file: file.name, size: file.size})
+		}
+	}
+	return append(cases, staticCase{name: "DirectoryMiss", kind: staticDirectoryMiss, file: staticMissDir + "/"})
+}
+
+// staticStaleETag is sent as If-None-Match to frameworks that send no ETag of their own
+const staticStaleETag = `"stale"`
+
+// newRequest builds the case request. etag is the ETag the framework returned for a plain GET.
+func (sc staticCase) newRequest(etag string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, staticPrefix+"/"+sc.file, nil)
+	switch sc.kind {
+	case staticIfModifiedSince:
+		req.Header.Set("If-Modified-Since", staticModTime.Format(http.TimeFormat))
+	case staticIfNoneMatch:
+		if etag == "" {
+			etag = staticStaleETag
+		}
+		req.Header.Set("If-None-Match", etag)
+	case staticRange:
+		req.Header.Set("Range", "bytes=0-"+strconv.Itoa(staticRangeLength-1))
+	}
+	return req
+}
+
+// want returns the accepted statuses and the expected body, nil when the body is not checked.
+// If-None-Match can only match when the framework sends an ETag; otherwise the full file is expected.
+// Frameworks refuse a directory without index either as not found or as forbidden.
+func (sc staticCase) want(etag string) ([]int, []byte) {
+	switch sc.kind {
+	case staticGet:
+		return []int{http.StatusOK}, staticContent(sc.size)
+	case staticIfNoneMatch:
+		if etag == "" {
+			return []int{http.StatusOK}, staticContent(sc.size)
+		}
+		return []int{http.StatusNotModified}, []byte{}
+	case staticIfModifiedSince:
+		return []int{http.StatusNotModified}, []byte{}
+	case staticRange:
+		return []int{http.StatusPartialContent}, staticContent(sc.size)[:staticRangeLength]
+	default:
+		return []int{http.StatusNotFound, http.StatusForbidden}, nil
+	}
+}
+
+// newStaticFramework builds fw serving root, and returns the ETag of file, if the framework sends one
+func newStaticFramework(fw frameworkFactory, root, file string) (Framework, string) {
+	f := fw.new()
+	f.Static(staticPrefix, root)
+
+	w := httptest.NewRecorder()
+	f.ServeHTTP(w, httptest.NewRequest(http.MethodGet, staticPrefix+"/"+file, nil))
+	return f, w.Header().Get("ETag")
+}
+
+// checkStatic serves one static case and reports how the response differs from the expected one
+func checkStatic(f Framework, sc staticCase, etag string) []string {
+	w := httptest.NewRecorder()
+	f.ServeHTTP(w, sc.newRequest(etag))
+
+	var problems []string
+	statuses, body := sc.want(etag)
+	if !slices.Contains(statuses, w.Code) {
+		problems = append(problems, fmt.Sprintf("status: want one of %v, got %d", statuses, w.Code))
+	}
+	if body != nil && !bytes.Equal(w.Body.Bytes(), body) {
+		problems = append(problems, fmt.Sprintf("body: want %d bytes, got %d", len(body), w.Body.Len()))
+	}
+	return problems
+}
+
+// TestStatic verifies every framework's static handler answers each case as expected
+func TestStatic(t *testing.T) {
+	root := writeStaticDir(t)
+
+	for _, fw := range frameworks {
+		t.Run(fw.name, func(t *testing.T) {
+			for _, sc := range staticCases() {
+				t.Run(sc.name, func(t *testing.T) {
+					f, etag := newStaticFramework(fw, root, sc.file)
+					for _, problem := range checkStatic(f, sc, etag) {
+						t.Error(problem)
+					}
+				})
+			}
+		})
+	}
+}
+
+// BenchmarkStatic serves generated files through every framework's static handler
+func BenchmarkStatic(b *testing.B) {
+	root := writeStaticDir(b)
+
+	for _, fw := range frameworks {
+		b.Run(fw.name, func(b *testing.B) {
+			for _, sc := range staticCases() {
+				b.Run(sc.name, func(b *testing.B) {
+					f, etag := newStaticFramework(fw, root, sc.file)
+					if problems := checkStatic(f, sc, etag); len(problems) > 0 {
+						b.Fatalf("unexpected response: %v", problems)
+					}
+
+					req := sc.newRequest(etag)
+					w := &discardResponseWriter{}
+
+					b.ResetTimer()
+					b.ReportAllocs()
+
+					for i := 0; i < b.N; i++ {
+						w.reset()
+						f.ServeHTTP(w, req)
+					}
+				})
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// standardHTTPPatternsFramework adapts a Go 1.22+ http.ServeMux to the Framework interface.
+// Routes are registered as method-qualified patterns with `{name}` wildcards, so the mux does the
+// method check, parameter parsing and 405 responses, and handlers read parameters with r.PathValue.
+type standardHTTPPatternsFramework struct {
+	mux *http.ServeMux
+
+	middlewares []MiddlewareFunc
+	handler     http.Handler // mux wrapped by middlewares, outermost first
+}
+
+func newStandardHTTPPatternsFramework() Framework {
+	mux := http.NewServeMux()
+	return &standardHTTPPatternsFramework{mux: mux, handler: mux}
+}
+
+func (f *standardHTTPPatternsFramework) GET(path string, h HandlerFunc) {
+	f.Handle(http.MethodGet, path, h)
+}
+
+func (f *standardHTTPPatternsFramework) POST(path string, h HandlerFunc) {
+	f.Handle(http.MethodPost, path, h)
+}
+
+func (f *standardHTTPPatternsFramework) Handle(method, path string, h HandlerFunc) {
+	path, patterns := compileRegexRoute(path)
+
+	f.mux.HandleFunc(method+" "+serveMuxPattern(path), func(w http.ResponseWriter, r *http.Request) {
+		c := serveMuxContext{&standardHTTPContext{w: w, r: r, patterns: patterns}}
+		if err := h(c); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
+
+// Use rebuilds the handler chain so the first middleware registered is the outermost one
+func (f *standardHTTPPatternsFramework) Use(m MiddlewareFunc) {
+	f.middlewares = append(f.middlewares, m)
+
+	f.handler = f.mux
+	for i := len(f.middlewares) - 1; i >= 0; i-- {
+		f.handler = standardHTTPMiddleware(f.middlewares[i], f.handler)
+	}
+}
+
+func (f *standardHTTPPatternsFramework) Static(prefix, root string) {
+	f.mux.Handle("GET "+prefix+"/", http.StripPrefix(prefix, http.FileServer(noListingFS{http.Dir(root)})))
+}
+
+func (f *standardHTTPPatternsFramework) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.handler.ServeHTTP(w, r)
+}
+
+// serveMuxPattern rewrites `:name` segments to `{name}` wildcards. A trailing slash is anchored
+// with `{$}`, otherwise ServeMux would treat the route as a whole subtree.
+func serveMuxPattern(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+
+	pattern := strings.Join(segments, "/")
+	if strings.HasSuffix(pattern, "/") {
+		pattern += "{$}"
+	}
+	return pattern
+}
+
+// serveMuxContext reads route parameters from r.PathValue instead of a parsed map
+type serveMuxContext struct {
+	*standardHTTPContext
+}
+
+func (c serveMuxContext) Param(name string) string {
+	return c.r.PathValue(name)
+}
+
+func (c serveMuxContext) RegexParam(index int) (string, error) {
+	return regexParam(c, c.patterns, index)
+}
+
+func TestServeMuxPattern(t *testing.T) {
+	tests := []struct{ path, want string }{
+		{"/hello", "/hello"},
+		{"/", "/{$}"},
+		{"/users/:id/posts/:postId", "/users/{id}/posts/{postId}"},
+		{"/repos/:owner/:repo/git/refs/", "/repos/{owner}/{repo}/git/refs/{$}"},
+	}
+
+	for _, tt := range tests {
+		if got := serveMuxPattern(tt.path); got != tt.want {
+			t.Errorf("serveMuxPattern(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
@@ -1,321 +1,311 @@
 package main
 
 import (
-	"bytes"
 	"encoding/json"
+	"fmt"
+	"mime/multipart"
 	"net/http"
-	"net/http/httptest"
+	"net/url"
+	"os"
+	"path"
+	"reflect"
+	"regexp"
+	"slices"
 	"strconv"
 	"strings"
-	"testing"
 )
 
-// StandardHTTP Mux setup
-func setupStandardHTTP() *http.ServeMux {
-	mux := http.NewServeMux()
-
-	// Simple GET handler
-	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
-		if r.Method != http.MethodGet {
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-			return
-		}
-		w.WriteHeader(http.StatusOK)
-		w.Write([]byte("Hello, Standard HTTP!"))
-	})
-
-	// JSON response handler with URL parameter parsing
-	mux.HandleFunc("/user/", func(w http.ResponseWriter, r *http.Request) {
-		if r.Method != http.MethodGet {
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-			return
-		}
-
-		// Extract ID from URL path
-		path := strings.TrimPrefix(r.URL.Path, "/user/")
-		if path == "" {
-			http.Error(w, "Invalid ID", http.StatusBadRequest)
-			return
-		}
-
-		user := User{
-			ID:    1,
-			Name:  "John Doe",
-			Email: "john@example.com",
-		}
-
-		response := map[string]interface{}{
-			"id":   path,
-			"user": user,
-		}
+// standardHTTPFramework adapts a pre-Go 1.22 style http.ServeMux to the Framework interface.
+// Routes are registered under their static prefix and the handler does the method check and
+// parameter parsing by hand, the way net/http services were written before pattern routing.
+type standardHTTPFramework struct {
+	mux    *http.ServeMux
+	routes map[string][]standardHTTPRoute // keyed by the ServeMux pattern serving them
 
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		json.NewEncoder(w).Encode(response)
-	})
+	middlewares []MiddlewareFunc
+	handler     http.Handler // mux wrapped by middlewares, outermost first
+}
 
-	// POST handler with JSON body
-	mux.HandleFunc("/users", func(w http.ResponseWriter, r *http.Request) {
-		if r.Method != http.MethodPost {
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-			return
-		}
+type standardHTTPRoute struct {
+	method   string
+	segments []string
+	patterns []*regexp.Regexp
+	handler  HandlerFunc
+}
 
-		var user User
-		if err := json.NewDecoder(r.Body).Decode(&user); err != nil {
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusBadRequest)
-			json.NewEncoder(w).Encode(map[string]string{"error": "Invalid JSON"})
-			return
-		}
+func newStandardHTTPFramework() Framework {
+	mux := http.NewServeMux()
+	return &standardHTTPFramework{
+		mux:     mux,
+		routes:  make(map[string][]standardHTTPRoute),
+		handler: mux,
+	}
+}
 
-		user.ID = 123
-		response := map[string]interface{}{
-			"id":    user.ID,
-			"name":  user.Name,
-			"email": user.Email,
-		}
+func (f *standardHTTPFramework) GET(path string, h HandlerFunc) {
+	f.Handle(http.MethodGet, path, h)
+}
 
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusCreated)
-		json.NewEncoder(w).Encode(response)
-	})
+func (f *standardHTTPFramework) POST(path string, h HandlerFunc) {
+	f.Handle(http.MethodPost, path, h)
+}
 
-	// Multiple route parameters (simplified)
-	mux.HandleFunc("/users/", func(w http.ResponseWriter, r *http.Request) {
-		if r.Method != http.MethodGet {
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-			return
-		}
+func (f *standardHTTPFramework) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.handler.ServeHTTP(w, r)
+}
 
-		// Simple parsing of /users/:id/posts/:postId
-		path := strings.TrimPrefix(r.URL.Path, "/users/")
-		parts := strings.Split(path, "/")
+// Use rebuilds the handler chain so the first middleware registered is the outermost one
+func (f *standardHTTPFramework) Use(m MiddlewareFunc) {
+	f.middlewares = append(f.middlewares, m)
 
-		if len(parts) >= 3 && parts[1] == "posts" {
-			userID := parts[0]
-			postID := parts[2]
+	f.handler = f.mux
+	for i := len(f.middlewares) - 1; i >= 0; i-- {
+		f.handler = standardHTTPMiddleware(f.middlewares[i], f.handler)
+	}
+}
 
-			response := map[string]string{
-				"userId": userID,
-				"postId": postID,
-			}
+func (f *standardHTTPFramework) Static(prefix, root string) {
+	f.mux.Handle(prefix+"/", http.StripPrefix(prefix, http.FileServer(noListingFS{http.Dir(root)})))
+}
 
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusOK)
-			json.NewEncoder(w).Encode(response)
-			return
-		}
+// noListingFS hides directories without an index.html from http.FileServer, which would otherwise list them
+type noListingFS struct {
+	fs http.FileSystem
+}
 
-		http.Error(w, "Not found", http.StatusNotFound)
-	})
+func (fs noListingFS) Open(name string) (http.File, error) {
+	file, err := fs.fs.Open(name)
+	if err != nil {
+		return nil, err
+	}
 
-	// Query parameters
-	mux.HandleFunc("/search", func(w http.ResponseWriter, r *http.Request) {
-		if r.Method != http.MethodGet {
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-			return
+	if info, err := file.Stat(); err == nil && info.IsDir() {
+		index, err := fs.fs.Open(path.Join(name, "index.html"))
+		if err != nil {
+			file.Close()
+			return nil, os.ErrNotExist
 		}
+		index.Close()
+	}
+	return file, nil
+}
 
-		query := r.URL.Query().Get("q")
-		limit := r.URL.Query().Get("limit")
-
-		response := map[string]string{
-			"query": query,
-			"limit": limit,
+// standardHTTPMiddleware wraps next the usual func(http.Handler) http.Handler way
+func standardHTTPMiddleware(m MiddlewareFunc, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		err := m(&standardHTTPContext{w: w, r: r}, func() error {
+			next.ServeHTTP(w, r)
+			return nil
+		})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
 		}
-
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		json.NewEncoder(w).Encode(response)
 	})
-
-	return mux
 }
 
-// Standard HTTP Benchmark simple GET request
-func BenchmarkStandardHTTPSimpleGET(b *testing.B) {
-	mux := setupStandardHTTP()
-	req := httptest.NewRequest("GET", "/", nil)
+func (f *standardHTTPFramework) Handle(method, path string, h HandlerFunc) {
+	path, patterns := compileRegexRoute(path)
 
-	b.ResetTimer()
-	b.ReportAllocs()
+	// Serve parameterized routes from the subtree of their static prefix, e.g. /user/:id under /user/
+	pattern := path
+	if i := strings.Index(path, "/:"); i != -1 {
+		pattern = path[:i+1]
+	}
 
-	for i := 0; i < b.N; i++ {
-		w := httptest.NewRecorder()
-		mux.ServeHTTP(w, req)
+	if _, ok := f.routes[pattern]; !ok {
+		f.mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+			f.dispatch(pattern, w, r)
+		})
 	}
+
+	f.routes[pattern] = append(f.routes[pattern], standardHTTPRoute{
+		method:   method,
+		segments: strings.Split(path, "/"),
+		patterns: patterns,
+		handler:  h,
+	})
 }
 
-// Standard HTTP Benchmark GET request with route parameter
-func BenchmarkStandardHTTPGetWithParam(b *testing.B) {
-	mux := setupStandardHTTP()
-	req := httptest.NewRequest("GET", "/user/123", nil)
+func (f *standardHTTPFramework) dispatch(pattern string, w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(r.URL.Path, "/")
 
-	b.ResetTimer()
-	b.ReportAllocs()
+	methodMismatch := false
+	for _, route := range f.routes[pattern] {
+		params, ok := matchSegments(route.segments, parts)
+		if !ok {
+			continue
+		}
+		if r.Method != route.method {
+			methodMismatch = true
+			continue
+		}
 
-	for i := 0; i < b.N; i++ {
-		w := httptest.NewRecorder()
-		mux.ServeHTTP(w, req)
+		if err := route.handler(&standardHTTPContext{w: w, r: r, params: params, patterns: route.patterns}); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
 	}
-}
 
-// Standard HTTP Benchmark GET request with multiple route parameters
-func BenchmarkStandardHTTPGetWithMultipleParams(b *testing.B) {
-	mux := setupStandardHTTP()
-	req := httptest.NewRequest("GET", "/users/123/posts/456", nil)
-
-	b.ResetTimer()
-	b.ReportAllocs()
-
-	for i := 0; i < b.N; i++ {
-		w := httptest.NewRecorder()
-		mux.ServeHTTP(w, req)
+	if methodMismatch {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
 	}
+	http.Error(w, "Not found", http.StatusNotFound)
 }
 
-// Standard HTTP Benchmark GET request with query parameters
-func BenchmarkStandardHTTPGetWithQueryParams(b *testing.B) {
-	mux := setupStandardHTTP()
-	req := httptest.NewRequest("GET", "/search?q=golang&limit=10", nil)
-
-	b.ResetTimer()
-	b.ReportAllocs()
+// matchSegments matches request path segments against route segments, collecting `:name` parameters
+func matchSegments(route, parts []string) (map[string]string, bool) {
+	if len(route) != len(parts) {
+		return nil, false
+	}
 
-	for i := 0; i < b.N; i++ {
-		w := httptest.NewRecorder()
-		mux.ServeHTTP(w, req)
+	var params map[string]string
+	for i, segment := range route {
+		if strings.HasPrefix(segment, ":") {
+			if parts[i] == "" {
+				return nil, false
+			}
+			if params == nil {
+				params = make(map[string]string)
+			}
+			params[segment[1:]] = parts[i]
+			continue
+		}
+		if segment != parts[i] {
+			return nil, false
+		}
 	}
+	return params, true
 }
 
-// Standard HTTP Benchmark POST request with JSON payload
-func BenchmarkStandardHTTPPostWithJSON(b *testing.B) {
-	mux := setupStandardHTTP()
+// decodeValues sets the fields of the struct v points to from values, matched by the given struct tag.
+// It covers the field kinds the scenarios bind: strings, integers, floats, bools and string slices.
+func decodeValues(values url.Values, v any, tag string) error {
+	rv := reflect.ValueOf(v).Elem()
+	rt := rv.Type()
+
+	for i := 0; i < rt.NumField(); i++ {
+		name := rt.Field(i).Tag.Get(tag)
+		vals := values[name]
+		if name == "" || len(vals) == 0 {
+			continue
+		}
 
-	user := User{
-		Name:  "Test User",
-		Email: "test@example.com",
+		field := rv.Field(i)
+		switch field.Kind() {
+		case reflect.String:
+			field.SetString(vals[0])
+		case reflect.Int, reflect.Int64:
+			n, err := strconv.ParseInt(vals[0], 10, 64)
+			if err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+			field.SetInt(n)
+		case reflect.Float64:
+			n, err := strconv.ParseFloat(vals[0], 64)
+			if err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+			field.SetFloat(n)
+		case reflect.Bool:
+			b, err := strconv.ParseBool(vals[0])
+			if err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+			field.SetBool(b)
+		case reflect.Slice:
+			if field.Type().Elem().Kind() != reflect.String {
+				return fmt.Errorf("%s: unsupported slice of %s", name, field.Type().Elem())
+			}
+			field.Set(reflect.ValueOf(slices.Clone(vals)))
+		default:
+			return fmt.Errorf("%s: unsupported field kind %s", name, field.Kind())
+		}
 	}
+	return nil
+}
 
-	jsonData, _ := json.Marshal(user)
-
-	b.ResetTimer()
-	b.ReportAllocs()
-
-	for i := 0; i < b.N; i++ {
-		req := httptest.NewRequest("POST", "/users", bytes.NewBuffer(jsonData))
-		req.Header.Set("Content-Type", "application/json")
-		w := httptest.NewRecorder()
-		mux.ServeHTTP(w, req)
-	}
+// standardHTTPContext implements Context on top of http.ResponseWriter and *http.Request
+type standardHTTPContext struct {
+	w        http.ResponseWriter
+	r        *http.Request
+	params   map[string]string
+	patterns []*regexp.Regexp
 }
 
-// Standard HTTP Benchmark routing performance with different number of routes
-func BenchmarkStandardHTTPRouting10Routes(b *testing.B) {
-	benchmarkStandardHTTPRouting(b, 10)
+func (c *standardHTTPContext) Param(name string) string {
+	return c.params[name]
 }
 
-func BenchmarkStandardHTTPRouting100Routes(b *testing.B) {
-	benchmarkStandardHTTPRouting(b, 100)
+func (c *standardHTTPContext) RegexParam(index int) (string, error) {
+	return regexParam(c, c.patterns, index)
 }
 
-func BenchmarkStandardHTTPRouting1000Routes(b *testing.B) {
-	benchmarkStandardHTTPRouting(b, 1000)
+func (c *standardHTTPContext) Query(name string) string {
+	return c.r.URL.Query().Get(name)
 }
 
-func benchmarkStandardHTTPRouting(b *testing.B, numRoutes int) {
-	mux := http.NewServeMux()
+func (c *standardHTTPContext) BindJSON(v any) error {
+	return json.NewDecoder(c.r.Body).Decode(v)
+}
 
-	// Create multiple routes
-	for i := 0; i < numRoutes; i++ {
-		route := "/route" + strconv.Itoa(i)
-		routeIndex := i // Capture loop variable
-		mux.HandleFunc(route, func(w http.ResponseWriter, r *http.Request) {
-			w.WriteHeader(http.StatusOK)
-			w.Write([]byte("Route " + strconv.Itoa(routeIndex)))
-		})
+func (c *standardHTTPContext) BindForm(v any) error {
+	var err error
+	if strings.HasPrefix(c.r.Header.Get("Content-Type"), "multipart/form-data") {
+		err = c.r.ParseMultipartForm(32 << 20)
+	} else {
+		err = c.r.ParseForm()
 	}
-
-	// Test the last route (worst case scenario)
-	req := httptest.NewRequest("GET", "/route"+strconv.Itoa(numRoutes-1), nil)
-
-	b.ResetTimer()
-	b.ReportAllocs()
-
-	for i := 0; i < b.N; i++ {
-		w := httptest.NewRecorder()
-		mux.ServeHTTP(w, req)
+	if err != nil {
+		return err
 	}
+	return decodeValues(c.r.PostForm, v, "form")
 }
 
-// Standard HTTP Benchmark concurrent requests
-func BenchmarkStandardHTTPConcurrentRequests(b *testing.B) {
-	mux := setupStandardHTTP()
-	req := httptest.NewRequest("GET", "/", nil)
-
-	b.ResetTimer()
-	b.ReportAllocs()
-
-	b.RunParallel(func(pb *testing.PB) {
-		for pb.Next() {
-			w := httptest.NewRecorder()
-			mux.ServeHTTP(w, req)
-		}
-	})
+func (c *standardHTTPContext) BindQuery(v any) error {
+	return decodeValues(c.r.URL.Query(), v, "query")
 }
 
-// Standard HTTP Benchmark memory allocations for different payload sizes
-func BenchmarkStandardHTTPSmallPayload(b *testing.B) {
-	benchmarkStandardHTTPPayloadSize(b, 100)
+func (c *standardHTTPContext) FormFile(name string) (*multipart.FileHeader, error) {
+	file, header, err := c.r.FormFile(name)
+	if err != nil {
+		return nil, err
+	}
+	file.Close()
+	return header, nil
 }
 
-func BenchmarkStandardHTTPMediumPayload(b *testing.B) {
-	benchmarkStandardHTTPPayloadSize(b, 1024)
+func (c *standardHTTPContext) JSON(code int, body H) error {
+	c.w.Header().Set("Content-Type", "application/json")
+	c.w.WriteHeader(code)
+	return json.NewEncoder(c.w).Encode(body)
 }
 
-func BenchmarkStandardHTTPLargePayload(b *testing.B) {
-	benchmarkStandardHTTPPayloadSize(b, 10240)
+func (c *standardHTTPContext) String(code int, s string) error {
+	c.w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	c.w.WriteHeader(code)
+	_, err := c.w.Write([]byte(s))
+	return err
 }
 
-func benchmarkStandardHTTPPayloadSize(b *testing.B, size int) {
-	mux := http.NewServeMux()
-
-	mux.HandleFunc("/data", func(w http.ResponseWriter, r *http.Request) {
-		if r.Method != http.MethodPost {
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-			return
-		}
-
-		// Read and echo back the data
-		var data map[string]interface{}
-		if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusBadRequest)
-			json.NewEncoder(w).Encode(map[string]string{"error": "Invalid JSON"})
-			return
-		}
-
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		json.NewEncoder(w).Encode(data)
-	})
+func (c *standardHTTPContext) Method() string {
+	return c.r.Method
+}
 
-	// Create payload of specified size
-	payload := make(map[string]string)
-	for i := 0; i < size/10; i++ { // Approximate size control
-		payload["key"+strconv.Itoa(i)] = "value" + strconv.Itoa(i)
-	}
+func (c *standardHTTPContext) Path() string {
+	return c.r.URL.Path
+}
 
-	jsonData, _ := json.Marshal(payload)
+func (c *standardHTTPContext) Header(name string) string {
+	return c.r.Header.Get(name)
+}
 
-	b.ResetTimer()
-	b.ReportAllocs()
+func (c *standardHTTPContext) SetHeader(name, value string) {
+	c.w.Header().Set(name, value)
+}
 
-	for i := 0; i < b.N; i++ {
-		req := httptest.NewRequest("POST", "/data", bytes.NewBuffer(jsonData))
-		req.Header.Set("Content-Type", "application/json")
-		w := httptest.NewRecorder()
-		mux.ServeHTTP(w, req)
-	}
+func (c *standardHTTPContext) Stream(code int, contentType string, write StreamFunc) error {
+	c.w.Header().Set("Content-Type", contentType)
+	c.w.WriteHeader(code)
+	return write(c.w, http.NewResponseController(c.w).Flush)
 }
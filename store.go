@@ -0,0 +1,139 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"sync"
+)
+
+var (
+	// ErrProductNotFound is returned for an ID the store does not hold
+	ErrProductNotFound = errors.New("product not found")
+	// ErrDuplicateSKU is returned when another product already uses the SKU
+	ErrDuplicateSKU = errors.New("a product with this SKU already exists")
+)
+
+// ProductStore is an in-memory product catalog, safe for concurrent use. IDs are assigned on
+// create, starting at 1, and are never reused; SKUs are unique across the catalog.
+type ProductStore struct {
+	mu       sync.RWMutex
+	products map[int]Product
+	skus     map[string]int // SKU to product ID
+	ids      []int          // IDs in increasing order; IDs only grow, so creates append
+	lastID   int
+}
+
+func NewProductStore() *ProductStore {
+	return &ProductStore{products: make(map[int]Product), skus: make(map[string]int)}
+}
+
+// Create stores p under a new ID, ignoring p.ID, and returns the stored product
+func (s *ProductStore) Create(p Product) (Product, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, taken := s.skus[p.SKU]; taken {
+		return Product{}, ErrDuplicateSKU
+	}
+
+	s.lastID++
+	p.ID = s.lastID
+	p = cloneProduct(p)
+	s.products[p.ID] = p
+	s.skus[p.SKU] = p.ID
+	s.ids = append(s.ids, p.ID)
+	return cloneProduct(p), nil
+}
+
+func (s *ProductStore) Get(id int) (Product, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	p, ok := s.products[id]
+	if !ok {
+		return Product{}, ErrProductNotFound
+	}
+	return cloneProduct(p), nil
+}
+
+// List returns the products match accepts, ordered by ID; the slice is empty, not nil, when
+// nothing matches, so it encodes as a JSON array
+func (s *ProductStore) List(match func(Product) bool) []Product {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	products := []Product{}
+	for _, id := range s.ids {
+		if p := s.products[id]; match(p) {
+			products = append(products, cloneProduct(p))
+		}
+	}
+	return products
+}
+
+// Update replaces the product stored under id with p and returns the stored product
+func (s *ProductStore) Update(id int, p Product) (Product, error) {
+	return s.UpdateFunc(id, func(Product) (Product, error) { return p, nil })
+}
+
+// UpdateFunc replaces the product stored under id with what update returns for it, holding the
+// store locked so concurrent read-modify-write updates of one product cannot overwrite each
+// other. An error from update is returned as is and leaves the product unchanged.
+func (s *ProductStore) UpdateFunc(id int, update func(Product) (Product, error)) (Product, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	old, ok := s.products[id]
+	if !ok {
+		return Product{}, ErrProductNotFound
+	}
+	p, err := update(cloneProduct(old))
+	if err != nil {
+		return Product{}, err
+	}
+	if owner, taken := s.skus[p.SKU]; taken && owner != id {
+		return Product{}, ErrDuplicateSKU
+	}
+
+	delete(s.skus, old.SKU)
+	p.ID = id
+	p = cloneProduct(p)
+	s.products[id] = p
+	s.skus[p.SKU] = id
+	return cloneProduct(p), nil
+}
+
+func (s *ProductStore) Delete(id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.products[id]
+	if !ok {
+		return ErrProductNotFound
+	}
+	delete(s.products, id)
+	delete(s.skus, p.SKU)
+	if i, found := slices.BinarySearch(s.ids, id); found {
+		s.ids = slices.Delete(s.ids, i, i+1)
+	}
+	return nil
+}
+
+// cloneProduct copies Tags so callers never share a slice with the store
+func cloneProduct(p Product) Product {
+	p.Tags = slices.Clone(p.Tags)
+	return p
+}
+
+// storeErrorStatus maps a ProductStore error to the response status
+func storeErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrProductNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, ErrDuplicateSKU):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+)
+
+// withSKU returns the sample product with its SKU replaced
+func withSKU(sku string) Product {
+	p := sampleProduct
+	p.SKU = sku
+	return p
+}
+
+func TestProductStore(t *testing.T) {
+	s := NewProductStore()
+
+	tests := []struct {
+		name    string
+		op      func() (Product, error)
+		wantErr error
+		wantID  int
+	}{
+		{"create assigns the first ID", func() (Product, error) { return s.Create(withSKU("AAA00001")) }, nil, 1},
+		{"create ignores the given ID", func() (Product, error) {
+			p := withSKU("AAA00002")
+			p.ID = 99
+			return s.Create(p)
+		}, nil, 2},
+		{"create rejects a duplicate SKU", func() (Product, error) { return s.Create(withSKU("AAA00001")) }, ErrDuplicateSKU, 0},
+		{"get", func() (Product, error) { return s.Get(2) }, nil, 2},
+		{"get missing", func() (Product, error) { return s.Get(3) }, ErrProductNotFound, 0},
+		{"update keeping the SKU", func() (Product, error) { return s.Update(1, withSKU("AAA00001")) }, nil, 1},
+		{"update to another product's SKU", func() (Product, error) { return s.Update(1, withSKU("AAA00002")) }, ErrDuplicateSKU, 0},
+		{"update changing the SKU", func() (Product, error) { return s.Update(1, withSKU("AAA00003")) }, nil, 1},
+		{"the old SKU is free again", func() (Product, error) { return s.Create(withSKU("AAA00001")) }, nil, 3},
+		{"update missing", func() (Product, error) { return s.Update(42, withSKU("AAA00042")) }, ErrProductNotFound, 0},
+		{"delete", func() (Product, error) { return Product{}, s.Delete(2) }, nil, 0},
+		{"delete missing", func() (Product, error) { return Product{}, s.Delete(2) }, ErrProductNotFound, 0},
+		{"get deleted", func() (Product, error) { return s.Get(2) }, ErrProductNotFound, 0},
+		{"IDs are not reused", func() (Product, error) { return s.Create(withSKU("AAA00002")) }, nil, 4},
+	}
+
+	for _, tt := range tests {
+		p, err := tt.op()
+		if !errors.Is(err, tt.wantErr) {
+			t.Fatalf("%s: err = %v, want %v", tt.name, err, tt.wantErr)
+		}
+		if p.ID != tt.wantID {
+			t.Errorf("%s: ID = %d, want %d", tt.name, p.ID, tt.wantID)
+		}
+	}
+}
+
+func TestProductStoreList(t *testing.T) {
+	s := NewProductStore()
+	for _, sku := range []string{"AAA00001", "BBB00001", "AAA00002", "BBB00002"} {
+		s.Create(withSKU(sku))
+	}
+	s.Delete(2)
+
+	var ids []int
+	for _, p := range s.List(func(p Product) bool { return p.SKU[0] == 'A' || p.SKU == "BBB00002" }) {
+		ids = append(ids, p.ID)
+	}
+	if want := []int{1, 3, 4}; !slices.Equal(ids, want) {
+		t.Errorf("listed IDs = %v, want %v", ids, want)
+	}
+}
+
+func TestProductStoreUpdateFuncError(t *testing.T) {
+	s := NewProductStore()
+	created, _ := s.Create(withSKU("AAA00001"))
+
+	errRejected := errors.New("rejected")
+	_, err := s.UpdateFunc(created.ID, func(p Product) (Product, error) {
+		p.SKU = "AAA00002"
+		return p, errRejected
+	})
+	if !errors.Is(err, errRejected) {
+		t.Fatalf("err = %v, want %v", err, errRejected)
+	}
+	if got, _ := s.Get(created.ID); got.SKU != "AAA00001" {
+		t.Errorf("SKU = %s after a failed update, want AAA00001", got.SKU)
+	}
+}
+
+func TestProductStoreCopiesTags(t *testing.T) {
+	s := NewProductStore()
+	p := withSKU("AAA00001")
+	p.Tags = []string{"one", "two"}
+
+	created, err := s.Create(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Tags[0] = "changed"
+	created.Tags[1] = "changed"
+
+	got, _ := s.Get(created.ID)
+	if got.Tags[0] != "one" || got.Tags[1] != "two" {
+		t.Errorf("stored tags = %v, want [one two]", got.Tags)
+	}
+}
+
+// TestProductStoreConcurrent creates, reads, updates and deletes from many goroutines; run it
+// with -race. Every goroutine creates products with the same SKUs, so exactly one create per
+// SKU must succeed.
+func TestProductStoreConcurrent(t *testing.T) {
+	const (
+		workers = 8
+		skus    = 100
+	)
+	s := NewProductStore()
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		created = make(map[string]int)
+	)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < skus; i++ {
+				sku := benchmarkSKU(i)
+				p, err := s.Create(withSKU(sku))
+				if errors.Is(err, ErrDuplicateSKU) {
+					continue
+				}
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				mu.Lock()
+				created[sku]++
+				mu.Unlock()
+
+				s.Get(p.ID)
+				s.Update(p.ID, withSKU(sku))
+			}
+		}()
+	}
+	wg.Wait()
+
+	for i := 0; i < skus; i++ {
+		if n := created[benchmarkSKU(i)]; n != 1 {
+			t.Errorf("%s created %d times, want once", benchmarkSKU(i), n)
+		}
+	}
+	for id := 1; id <= skus; id++ {
+		if err := s.Delete(id); err != nil {
+			t.Errorf("delete %d: %v", id, err)
+		}
+	}
+	if len(s.products) != 0 || len(s.skus) != 0 {
+		t.Errorf("store not empty after deleting every product: %d products, %d SKUs", len(s.products), len(s.skus))
+	}
+}
@@ -0,0 +1,290 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/metrics"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// streamItem is one element of the large JSON response arrays
+type streamItem struct {
+	ID     int    `json:"id"`
+	Name   string `json:"name"`
+	Email  string `json:"email"`
+	Active bool   `json:"active"`
+}
+
+// streamItems returns enough items to encode to roughly size bytes of JSON
+func streamItems(size int) []streamItem {
+	item := streamItem{ID: 100000, Name: "User 100000", Email: "user100000@example.com", Active: true}
+	items := make([]streamItem, size/len(mustMarshal(item)))
+	for i := range items {
+		id := strconv.Itoa(i)
+		items[i] = streamItem{ID: i, Name: "User " + id, Email: "user" + id + "@example.com", Active: i%2 == 0}
+	}
+	return items
+}
+
+const (
+	streamChunk     = 4 << 10
+	streamChunks    = 256 // 1MB in total
+	streamSSEEvents = 1000
+	streamFileSize  = 1 << 20
+)
+
+// writeStreamFile writes the body served by the FileCopy scenario in a temporary directory
+func writeStreamFile(tb testing.TB) string {
+	tb.Helper()
+
+	path := filepath.Join(tb.TempDir(), "stream.txt")
+	if err := os.WriteFile(path, []byte(streamFileBody()), 0o644); err != nil {
+		tb.Fatal(err)
+	}
+	return path
+}
+
+func streamFileBody() string {
+	return strings.Repeat("0123456789abcdef", streamFileSize/16)
+}
+
+// streamingScenarios are built on demand; their golden bodies are megabytes large
+func streamingScenarios(tb testing.TB) []scenario {
+	path := writeStreamFile(tb)
+	chunk := []byte(strings.Repeat("x", streamChunk-1) + "\n")
+
+	var events strings.Builder
+	for i := 0; i < streamSSEEvents; i++ {
+		events.WriteString("id: " + strconv.Itoa(i) + "\ndata: {\"n\":" + strconv.Itoa(i) + "}\n\n")
+	}
+
+	return []scenario{
+		jsonArrayScenario("JSONArray1MB", 1<<20),
+		jsonArrayScenario("JSONArray10MB", 10<<20),
+		{
+			name: "ChunkedStream",
+			routes: func(f Framework) {
+				f.GET("/stream", func(c Context) error {
+					return c.Stream(http.StatusOK, "text/plain; charset=utf-8", func(w io.Writer, flush func() error) error {
+						for i := 0; i < streamChunks; i++ {
+							if _, err := w.Write(chunk); err != nil {
+								return err
+							}
+							if err := flush(); err != nil {
+								return err
+							}
+						}
+						return nil
+					})
+				})
+			},
+			method: http.MethodGet,
+			target: "/stream",
+			want:   response{status: http.StatusOK, contentType: "text/plain", body: strings.Repeat(string(chunk), streamChunks)},
+		},
+		{
+			name: "ServerSentEvents",
+			routes: func(f Framework) {
+				f.GET("/events", func(c Context) error {
+					c.SetHeader("Cache-Control", "no-cache")
+					return c.Stream(http.StatusOK, "text/event-stream", func(w io.Writer, flush func() error) error {
+						for i := 0; i < streamSSEEvents; i++ {
+							id := strconv.Itoa(i)
+							if _, err := io.WriteString(w, "id: "+id+"\ndata: {\"n\":"+id+"}\n\n"); err != nil {
+								return err
+							}
+							if err := flush(); err != nil {
+								return err
+							}
+						}
+						return nil
+					})
+				})
+			},
+			method: http.MethodGet,
+			target: "/events",
+			want:   response{status: http.StatusOK, contentType: "text/event-stream", body: events.String()},
+		},
+		{
+			name: "FileCopy",
+			routes: func(f Framework) {
+				f.GET("/file", func(c Context) error {
+					// The file is opened inside the stream because Fiber runs it after the handler returns
+					return c.Stream(http.StatusOK, "text/plain; charset=utf-8", func(w io.Writer, flush func() error) error {
+						file, err := os.Open(path)
+						if err != nil {
+							return err
+						}
+						defer file.Close()
+
+						_, err = io.Copy(w, file)
+						return err
+					})
+				})
+			},
+			method: http.MethodGet,
+			target: "/file",
+			want:   response{status: http.StatusOK, contentType: "text/plain", body: streamFileBody()},
+		},
+	}
+}
+
+// jsonArrayScenario responds with a JSON array of roughly size bytes, encoded in one call
+func jsonArrayScenario(name string, size int) scenario {
+	items := streamItems(size)
+	return scenario{
+		name: name,
+		routes: func(f Framework) {
+			f.GET("/items", func(c Context) error {
+				return c.JSON(http.StatusOK, H{"items": items})
+			})
+		},
+		method: http.MethodGet,
+		target: "/items",
+		want:   response{status: http.StatusOK, contentType: "application/json", body: string(mustMarshal(H{"items": items}))},
+	}
+}
+
+// discardResponseWriter counts the response body and throws it away, so large responses
+// are not buffered by the benchmark itself the way httptest.ResponseRecorder would
+type discardResponseWriter struct {
+	header  http.Header
+	status  int
+	written int64
+}
+
+func (w *discardResponseWriter) Header() http.Header {
+	if w.header == nil {
+		w.header = make(http.Header)
+	}
+	return w.header
+}
+
+func (w *discardResponseWriter) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+	}
+}
+
+func (w *discardResponseWriter) Write(p []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+	w.written += int64(len(p))
+	return len(p), nil
+}
+
+// Flush implements http.Flusher, so streaming handlers take the same path as on a real connection
+func (w *discardResponseWriter) Flush() {}
+
+func (w *discardResponseWriter) reset() {
+	clear(w.header)
+	w.status, w.written = 0, 0
+}
+
+// heapObjectsMetric is the heap memory occupied by objects, live or not yet swept
+const heapObjectsMetric = "/memory/classes/heap/objects:bytes"
+
+// sampleHeapPeak samples heap object bytes until the returned function is called, which returns
+// the highest level seen above the starting one. Sampling through runtime/metrics does not stop
+// the world, unlike runtime.ReadMemStats.
+func sampleHeapPeak() func() uint64 {
+	sample := []metrics.Sample{{Name: heapObjectsMetric}}
+	read := func() uint64 {
+		metrics.Read(sample)
+		return sample[0].Value.Uint64()
+	}
+
+	runtime.GC()
+	base := read()
+
+	stop, done := make(chan struct{}), make(chan uint64)
+	go func() {
+		ticker := time.NewTicker(200 * time.Microsecond)
+		defer ticker.Stop()
+
+		peak := base
+		for {
+			select {
+			case <-ticker.C:
+				peak = max(peak, read())
+			case <-stop:
+				done <- max(peak, read()) - base
+				return
+			}
+		}
+	}()
+
+	return func() uint64 {
+		close(stop)
+		return <-done
+	}
+}
+
+// TestStreamingConformance checks the streaming scenarios' golden responses. Bodies above 2MB
+// take seconds to compare as JSON; BenchmarkStreaming still checks them before measuring.
+func TestStreamingConformance(t *testing.T) {
+	for _, sc := range streamingScenarios(t) {
+		for _, fw := range frameworks {
+			t.Run(fw.name+"/"+sc.name, func(t *testing.T) {
+				if len(sc.want.body) > 2<<20 {
+					t.Skip("same handler as the smaller JSON array, checked by BenchmarkStreaming")
+				}
+				if err := checkConformance(fw, sc); err != nil {
+					t.Error(err)
+				}
+			})
+		}
+	}
+}
+
+// BenchmarkStreaming measures large and streamed responses. Besides B/op it reports peak-heap-B,
+// the highest heap level reached above the pre-benchmark level, which shows whether a response
+// was buffered in full.
+func BenchmarkStreaming(b *testing.B) {
+	streaming := streamingScenarios(b)
+	for _, sc := range streaming {
+		for _, fw := range frameworks {
+			if err := checkConformance(fw, sc); err != nil {
+				b.Fatalf("frameworks are not doing equivalent work:\n%v", err)
+			}
+		}
+	}
+
+	for _, fw := range frameworks {
+		b.Run(fw.name, func(b *testing.B) {
+			for _, sc := range streaming {
+				b.Run(sc.name, func(b *testing.B) {
+					runStreaming(b, fw, sc)
+				})
+			}
+		})
+	}
+}
+
+func runStreaming(b *testing.B, fw frameworkFactory, sc scenario) {
+	f := fw.new()
+	sc.routes(f)
+
+	w := &discardResponseWriter{}
+	req := sc.newRequest()
+	f.ServeHTTP(w, req)
+	size := w.written
+
+	stopSampling := sampleHeapPeak()
+	b.ResetTimer()
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		w.reset()
+		f.ServeHTTP(w, req)
+	}
+
+	b.StopTimer()
+	b.ReportMetric(float64(stopSampling()), "peak-heap-B")
+	b.SetBytes(size)
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/catalinfl/tree-framework"
+	"github.com/catalinfl/tree-framework/binding"
+)
+
+// FieldError is one `v:` rule a field failed
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+}
+
+// validateStruct checks every `v:` rule on the fields of the struct v points to and returns all
+// failures. tree's validator stops at the first failing rule, so each field is validated on its
+// own through a single-field struct carrying its tag, keeping tree's semantics for every rule.
+// Only the rules of a failing field are then run one by one to report each of them.
+func validateStruct(v any) []FieldError {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+
+	var failures []FieldError
+	for _, field := range validatedFields(rv.Type()) {
+		value := rv.Field(field.index)
+		if validateSingle(field.typ, value) == nil {
+			continue
+		}
+
+		for _, rule := range field.rules {
+			err := validateSingle(rule.typ, value)
+			if err == nil {
+				continue
+			}
+			// The validator wraps the rule's own message with the field name, which Field carries
+			message := err.Error()
+			if inner := errors.Unwrap(err); inner != nil {
+				message = inner.Error()
+			}
+			failures = append(failures, FieldError{
+				Field:   field.name,
+				Rule:    rule.name,
+				Param:   rule.param,
+				Message: message,
+			})
+		}
+	}
+	return failures
+}
+
+// validatedField is a struct field with `v:` rules, with the single-field struct types
+// validateStruct runs tree's validator on
+type validatedField struct {
+	index int
+	name  string       // JSON member name
+	typ   reflect.Type // the field with its whole tag
+	rules []validatedRule
+}
+
+// validatedRule is one rule of a validatedField
+type validatedRule struct {
+	name, param string
+	typ         reflect.Type // the field with this rule alone
+}
+
+// validatedFieldsCache holds the []validatedField of every struct type validateStruct has seen
+var validatedFieldsCache sync.Map
+
+// validatedFields returns the fields of struct type t that carry `v:` rules, building their
+// single-field struct types once per type
+func validatedFields(t reflect.Type) []validatedField {
+	if fields, ok := validatedFieldsCache.Load(t); ok {
+		return fields.([]validatedField)
+	}
+
+	var fields []validatedField
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("v")
+		if tag == "" || !field.IsExported() {
+			continue
+		}
+
+		f := validatedField{index: i, name: jsonName(field), typ: singleFieldType(field, tag)}
+		for _, rule := range strings.Split(tag, ";") {
+			rule = strings.TrimSpace(rule)
+			if rule == "" {
+				continue
+			}
+			name, param, _ := strings.Cut(rule, "=")
+			f.rules = append(f.rules, validatedRule{
+				name:  strings.TrimSpace(name),
+				param: strings.TrimSpace(param),
+				typ:   singleFieldType(field, rule),
+			})
+		}
+		fields = append(fields, f)
+	}
+
+	cached, _ := validatedFieldsCache.LoadOrStore(t, fields)
+	return cached.([]validatedField)
+}
+
+// jsonName returns the member name encoding/json uses for field
+func jsonName(field reflect.StructField) string {
+	if name, _, _ := strings.Cut(field.Tag.Get("json"), ","); name != "" && name != "-" {
+		return name
+	}
+	return field.Name
+}
+
+// singleFieldType is a struct with field alone, validated by rules
+func singleFieldType(field reflect.StructField, rules string) reflect.Type {
+	return reflect.StructOf([]reflect.StructField{{
+		Name: field.Name,
+		Type: field.Type,
+		Tag:  reflect.StructTag("v:" + strconv.Quote(rules)),
+	}})
+}
+
+// validateSingle runs tree's validator on value inside a struct of type typ
+func validateSingle(typ reflect.Type, value reflect.Value) error {
+	single := reflect.New(typ)
+	single.Elem().Field(0).Set(value)
+	return binding.Validator{}.Validate(single.Interface())
+}
+
+// decodeValid decodes a JSON body into v, over the values already in it, and checks every `v:`
+// rule, failing with 400 for malformed JSON and 422 listing every failing rule
+func decodeValid(body []byte, v any) error {
+	if err := json.Unmarshal(body, v); err != nil {
+		return &responseError{http.StatusBadRequest, tree.J{"error": "Invalid JSON format", "details": err.Error()}}
+	}
+	if failures := validateStruct(v); len(failures) > 0 {
+		return &responseError{http.StatusUnprocessableEntity, tree.J{"error": "Validation failed", "fields": failures}}
+	}
+	return nil
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+// docExample is one curl command of validation_examples.md
+type docExample struct {
+	title  string
+	method string
+	path   string
+	body   string
+}
+
+// docExamplePattern matches a numbered heading followed by its curl command, with an optional
+// single-quoted -d body
+var docExamplePattern = regexp.MustCompile("(?s)### (\\d+\\. [^\n]+)\n```bash\ncurl -X (\\w+) http://localhost:8080(\\S+)(.*?)```")
+
+var docBodyPattern = regexp.MustCompile(`(?s)-d '(.*)'`)
+
+// loadDocExamples reads every curl example of validation_examples.md, in document order
+func loadDocExamples(t *testing.T) []docExample {
+	t.Helper()
+
+	data, err := os.ReadFile("validation_examples.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var examples []docExample
+	for _, m := range docExamplePattern.FindAllStringSubmatch(string(data), -1) {
+		example := docExample{title: m[1], method: m[2], path: m[3]}
+		if body := docBodyPattern.FindStringSubmatch(m[4]); body != nil {
+			example.body = body[1]
+		}
+		examples = append(examples, example)
+	}
+	return examples
+}
+
+// TestValidationExamples replays every example of validation_examples.md against a fresh sample
+// application and checks its status and, for rejected bodies, the fields that failed
+func TestValidationExamples(t *testing.T) {
+	defer silenceStdout(t)()
+
+	tests := map[string]struct {
+		status int
+		fields []string // fields with at least one failing rule, in struct order
+	}{
+		"1. Valid User Registration Request":                {status: http.StatusCreated},
+		"2. Invalid User Registration - Username too short": {http.StatusUnprocessableEntity, []string{"username"}},
+		"3. Invalid User Registration - Age under 18":       {http.StatusUnprocessableEntity, []string{"age"}},
+		"4. Invalid User Registration - Invalid role":       {http.StatusUnprocessableEntity, []string{"role"}},
+		"5. Invalid User Registration - Terms not accepted": {http.StatusUnprocessableEntity, []string{"accept_terms"}},
+		"6. Valid Product Creation":                         {status: http.StatusCreated},
+		"7. Invalid Product - Price too low":                {http.StatusUnprocessableEntity, []string{"price"}},
+		"8. Invalid Product - Wrong SKU format":             {http.StatusUnprocessableEntity, []string{"sku"}},
+		"9. Test Validation Rules":                          {status: http.StatusOK},
+		"10. Health Check":                                  {status: http.StatusOK},
+	}
+
+	examples := loadDocExamples(t)
+	if len(examples) != len(tests) {
+		t.Errorf("found %d examples in validation_examples.md, want %d", len(examples), len(tests))
+	}
+
+	for _, example := range examples {
+		t.Run(example.title, func(t *testing.T) {
+			want, ok := tests[example.title]
+			if !ok {
+				t.Fatalf("no expected outcome for this example; add it to the test table")
+			}
+
+			req := httptest.NewRequest(example.method, example.path, strings.NewReader(example.body))
+			req.Header.Set("Content-Type", "application/json")
+
+			var body struct {
+				Fields []FieldError `json:"fields"`
+			}
+			w := serveApp(t, NewProductStore(), req, &body)
+			if w.Code != want.status {
+				t.Fatalf("status = %d, want %d; body %s", w.Code, want.status, w.Body)
+			}
+
+			var fields []string
+			for _, failure := range body.Fields {
+				if !slices.Contains(fields, failure.Field) {
+					fields = append(fields, failure.Field)
+				}
+			}
+			if !slices.Equal(fields, want.fields) {
+				t.Errorf("failing fields = %v, want %v; body %s", fields, want.fields, w.Body)
+			}
+		})
+	}
+}
+
+// TestRegisterPasswordRules checks each password character class is reported on its own
+func TestRegisterPasswordRules(t *testing.T) {
+	defer silenceStdout(t)()
+
+	tests := []struct {
+		password string
+		want     []string // params of the failing password rules
+	}{
+		{"MyP@ssw0rd123!", nil},
+		{"myp@ssw0rd123!", []string{"[A-Z]"}},
+		{"MYP@SSW0RD123!", []string{"[a-z]"}},
+		{"MyP@ssword!", []string{"[0-9]"}},
+		{"MyPassw0rd123", []string{"[^A-Za-z0-9]"}},
+		{"Ab1!", []string{"8"}},
+		{"password", []string{"[A-Z]", "[0-9]", "[^A-Za-z0-9]"}},
+	}
+
+	for _, tt := range tests {
+		req := RegisterRequest{
+			Username: "user1", Email: "john.doe@example.com", Password: tt.password,
+			FirstName: "John", LastName: "Doe", Age: 25, Phone: "+1234567890",
+			Address: "123 Main Street", ZipCode: "12345-6789", Role: "user",
+			AcceptTerms: true, Skills: []string{"Go"}, CountryCode: "US",
+		}
+
+		var params []string
+		for _, failure := range validateStruct(&req) {
+			if failure.Field != "password" {
+				t.Errorf("%s: unexpected failure %+v", tt.password, failure)
+			}
+			params = append(params, failure.Param)
+		}
+		if !slices.Equal(params, tt.want) {
+			t.Errorf("%s: failing password rules = %q, want %q", tt.password, params, tt.want)
+		}
+	}
+}
+
+// TestRegisterRequestRules breaks one field of a valid registration at a time
+func TestRegisterRequestRules(t *testing.T) {
+	defer silenceStdout(t)()
+
+	tests := []struct {
+		name   string
+		change func(*RegisterRequest)
+		field  string // empty when the request stays valid
+	}{
+		{"valid", func(r *RegisterRequest) {}, ""},
+		{"age 120", func(r *RegisterRequest) { r.Age = 120 }, ""},
+		{"age 121", func(r *RegisterRequest) { r.Age = 121 }, "age"},
+		{"zip plus four", func(r *RegisterRequest) { r.ZipCode = "12345-6789" }, ""},
+		{"zip with letters", func(r *RegisterRequest) { r.ZipCode = "1234A" }, "zip_code"},
+		{"zip too short", func(r *RegisterRequest) { r.ZipCode = "1234" }, "zip_code"},
+		{"no SSN", func(r *RegisterRequest) { r.SSN = "" }, ""},
+		{"SSN without dashes", func(r *RegisterRequest) { r.SSN = "123456789" }, "ssn"},
+		{"lowercase country code", func(r *RegisterRequest) { r.CountryCode = "us" }, "country_code"},
+		{"three letter country code", func(r *RegisterRequest) { r.CountryCode = "USA" }, "country_code"},
+		{"no skills", func(r *RegisterRequest) { r.Skills = nil }, "skills"},
+		{"eleven skills", func(r *RegisterRequest) { r.Skills = make([]string, 11) }, "skills"},
+		{"no website", func(r *RegisterRequest) { r.Website = "" }, ""},
+		{"website without scheme", func(r *RegisterRequest) { r.Website = "www.johndoe.com" }, "website"},
+		{"phone with letters", func(r *RegisterRequest) { r.Phone = "+1-800-FLOWERS" }, "phone"},
+		{"negative salary", func(r *RegisterRequest) { r.Salary = -1 }, "salary"},
+	}
+
+	for _, tt := range tests {
+		req := RegisterRequest{
+			Username: "user1", Email: "john.doe@example.com", Password: "MyP@ssw0rd123!",
+			FirstName: "John", LastName: "Doe", Age: 25, Phone: "+1234567890",
+			Address: "123 Main Street", ZipCode: "12345", Role: "user",
+			Website: "https://www.johndoe.com", AcceptTerms: true, Salary: 75000.5,
+			Skills: []string{"Go"}, CountryCode: "US", SSN: "123-45-6789",
+		}
+		tt.change(&req)
+
+		var fields []string
+		for _, failure := range validateStruct(&req) {
+			if !slices.Contains(fields, failure.Field) {
+				fields = append(fields, failure.Field)
+			}
+		}
+		var want []string
+		if tt.field != "" {
+			want = []string{tt.field}
+		}
+		if !slices.Equal(fields, want) {
+			t.Errorf("%s: failing fields = %v, want %v", tt.name, fields, want)
+		}
+	}
+}
+
+// TestValidationExamplesUnreadableBody checks the validation endpoints answer a body that fails
+// mid-read with a JSON 400, like malformed JSON
+func TestValidationExamplesUnreadableBody(t *testing.T) {
+	for _, target := range []string{"/users/register", "/validate/test"} {
+		req := httptest.NewRequest(http.MethodPost, target, iotest.ErrReader(errBodyRead))
+		req.Header.Set("Content-Type", "application/json")
+
+		var body struct {
+			Error string `json:"error"`
+		}
+		w := serveApp(t, NewProductStore(), req, &body)
+		if w.Code != http.StatusBadRequest || body.Error != "Invalid request body" {
+			t.Errorf("POST %s: got %d %s, want 400 Invalid request body", target, w.Code, w.Body)
+		}
+	}
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"regexp"
+	"sync"
+	"testing"
+
+	treebinding "github.com/catalinfl/tree-framework/binding"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// fixture is one named request body from test_requests.json
+type fixture struct {
+	name string
+	body []byte
+}
+
+// loadFixtures reads test_requests.json, keeping the order fixtures appear in the file
+func loadFixtures(tb testing.TB) []fixture {
+	tb.Helper()
+
+	data, err := os.ReadFile("test_requests.json")
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if _, err := dec.Token(); err != nil { // opening brace
+		tb.Fatal(err)
+	}
+
+	var fixtures []fixture
+	for dec.More() {
+		token, err := dec.Token()
+		if err != nil {
+			tb.Fatal(err)
+		}
+		var body json.RawMessage
+		if err := dec.Decode(&body); err != nil {
+			tb.Fatal(err)
+		}
+		fixtures = append(fixtures, fixture{name: token.(string), body: body})
+	}
+	return fixtures
+}
+
+// ginProduct mirrors Product's `v:` rules with go-playground/validator tags.
+// The validator has no inline regex rule, so the SKU pattern is registered once as "sku".
+type ginProduct struct {
+	ID          int      `json:"id"`
+	Name        string   `json:"name" binding:"required,min=3,max=100,alphanum"`
+	Description string   `json:"description" binding:"required,min=10"`
+	Price       float64  `json:"price" binding:"required,gt=0,lte=999999.99"`
+	Category    string   `json:"category" binding:"required,oneof=electronics clothing books home sports"`
+	SKU         string   `json:"sku" binding:"required,sku"`
+	InStock     bool     `json:"in_stock"`
+	Tags        []string `json:"tags" binding:"required,min=1,max=5"`
+}
+
+var (
+	skuPattern          = regexp.MustCompile(`^[A-Z]{3}\d{5}$`)
+	registerSKUValidate sync.Once
+)
+
+func registerGinValidations() {
+	registerSKUValidate.Do(func() {
+		v := binding.Validator.Engine().(*validator.Validate)
+		v.RegisterValidation("sku", func(fl validator.FieldLevel) bool {
+			return skuPattern.MatchString(fl.Field().String())
+		})
+	})
+}
+
+// validators binds and validates a JSON request into a fresh product, one entry per framework
+var validators = []struct {
+	name string
+	bind func(req *http.Request) error
+}{
+	{
+		name: "Tree",
+		bind: func(req *http.Request) error {
+			var p Product
+			return treebinding.JSON.Bind(req, &p)
+		},
+	},
+	{
+		name: "Gin",
+		bind: func(req *http.Request) error {
+			registerGinValidations()
+			var p ginProduct
+			return binding.JSON.Bind(req, &p)
+		},
+	},
+}
+
+// rewindableRequest is a POST request whose body can be replayed without rebuilding the request
+type rewindableRequest struct {
+	req  *http.Request
+	body *bytes.Reader
+	data []byte
+}
+
+func newRewindableRequest(data []byte) *rewindableRequest {
+	r := &rewindableRequest{body: bytes.NewReader(data), data: data}
+	r.req = httptest.NewRequest(http.MethodPost, "/product", nil)
+	r.req.Header.Set("Content-Type", "application/json")
+	r.req.Body = io.NopCloser(r.body)
+	return r
+}
+
+func (r *rewindableRequest) rewind() *http.Request {
+	r.body.Reset(r.data)
+	return r.req
+}
+
+// silenceStdout discards stdout until the returned function is called.
+// tree's validator prints a line for every field that passes validation, and its form and
+// query binders print every tag and query they decode.
+func silenceStdout(tb testing.TB) func() {
+	tb.Helper()
+
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = devNull
+	return func() {
+		os.Stdout = stdout
+		devNull.Close()
+	}
+}
+
+// validationOutcomes returns whether each fixture passed validation, per validator
+func validationOutcomes(tb testing.TB, fixtures []fixture) map[string]map[string]error {
+	defer silenceStdout(tb)()
+
+	outcomes := make(map[string]map[string]error)
+	for _, v := range validators {
+		outcomes[v.name] = make(map[string]error)
+		for _, fx := range fixtures {
+			outcomes[v.name][fx.name] = v.bind(newRewindableRequest(fx.body).rewind())
+		}
+	}
+	return outcomes
+}
+
+// TestValidationEquivalence verifies tree and Gin accept and reject the same fixtures,
+// so BenchmarkValidation compares equivalent work
+func TestValidationEquivalence(t *testing.T) {
+	fixtures := loadFixtures(t)
+	outcomes := validationOutcomes(t, fixtures)
+
+	for _, fx := range fixtures {
+		treeErr, ginErr := outcomes["Tree"][fx.name], outcomes["Gin"][fx.name]
+		if (treeErr == nil) != (ginErr == nil) {
+			t.Errorf("%s: tree %s, gin %s", fx.name, describeOutcome(treeErr), describeOutcome(ginErr))
+		}
+	}
+}
+
+// BenchmarkValidation binds and validates every fixture of test_requests.json, both passing and failing ones
+func BenchmarkValidation(b *testing.B) {
+	fixtures := loadFixtures(b)
+	outcomes := validationOutcomes(b, fixtures)
+	for _, fx := range fixtures {
+		if (outcomes["Tree"][fx.name] == nil) != (outcomes["Gin"][fx.name] == nil) {
+			b.Fatalf("%s: tree and gin disagree, run TestValidationEquivalence", fx.name)
+		}
+	}
+
+	for _, v := range validators {
+		b.Run(v.name, func(b *testing.B) {
+			for _, fx := range fixtures {
+				b.Run(fx.name, func(b *testing.B) {
+					runValidation(b, v.bind, fx)
+				})
+			}
+		})
+	}
+}
+
+func runValidation(b *testing.B, bind func(*http.Request) error, fx fixture) {
+	req := newRewindableRequest(fx.body)
+
+	restore := silenceStdout(b)
+	defer restore()
+
+	b.ResetTimer()
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		bind(req.rewind())
+	}
+}
+
+// describeOutcome is used in failure messages
+func describeOutcome(err error) string {
+	if err == nil {
+		return "accepted"
+	}
+	return fmt.Sprintf("rejected (%v)", err)
+}